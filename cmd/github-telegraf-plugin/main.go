@@ -7,13 +7,16 @@ import (
 	"time"
 
 	_ "github.com/hdecarne-github/github-telegraf-plugin/plugins/inputs/github"
+	_ "github.com/hdecarne-github/github-telegraf-plugin/plugins/inputs/github_webhooks"
+	_ "github.com/hdecarne-github/github-telegraf-plugin/plugins/outputs/github"
+	_ "github.com/hdecarne-github/github-telegraf-plugin/plugins/processors/github_enrich"
 
 	"github.com/influxdata/telegraf/plugins/common/shim"
 )
 
 var pollInterval = flag.Duration("poll_interval", 1*time.Second, "how often to send metrics")
 var pollIntervalDisabled = flag.Bool("poll_interval_disabled", false, "set to true to disable polling. You want to use this when you are sending metrics on your own schedule")
-var configFile = flag.String("config", "", "path to the config file for this plugin")
+var configFile = flag.String("config", "", "path to the config file for this plugin (required)")
 var err error
 
 // This is designed to be simple; Just change the import above and you're good.
@@ -41,10 +44,12 @@ func main() {
 	// create the shim. This is what will run your plugins.
 	shimLayer := shim.New()
 
-	// If no config is specified, all imported plugins are loaded.
-	// otherwise, follow what the config asks for.
-	// Check for settings from a config toml file,
-	// (or just use whatever plugins were imported above)
+	// As multiple plugins are imported above, the config is required to select
+	// the plugin to run (without a config, the shim would pick an arbitrary one).
+	if *configFile == "" {
+		fmt.Fprintln(os.Stderr, "Err loading input: missing config file (-config)")
+		os.Exit(1)
+	}
 	err = shimLayer.LoadConfig(configFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Err loading input: %s\n", err)
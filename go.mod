@@ -8,7 +8,6 @@ require (
 	github.com/google/go-github/v44 v44.1.0
 	github.com/influxdata/telegraf v1.29.2
 	github.com/stretchr/testify v1.8.4
-	golang.org/x/oauth2 v0.16.0
 )
 
 require (
@@ -85,6 +84,7 @@ require (
 	golang.org/x/crypto v0.18.0 // indirect
 	golang.org/x/exp v0.0.0-20240112132812-db7319d0e0e3 // indirect
 	golang.org/x/mod v0.14.0 // indirect
+	golang.org/x/oauth2 v0.16.0 // indirect
 	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/tools v0.17.0 // indirect
@@ -0,0 +1,66 @@
+// anonymous.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"errors"
+	"time"
+)
+
+// anonymousRepoRequests is the number of requests needed to gather a repo in anonymous mode (repo info and the first
+// release page).
+const anonymousRepoRequests = 2
+
+// checkAnonymous ensures the anonymous mode is not mixed up with authenticated access.
+func (plugin *GitHub) checkAnonymous() error {
+	if plugin.Anonymous && (!plugin.AccessToken.Empty() || !plugin.BackupAccessToken.Empty() || len(plugin.AccessTokens) > 0 || plugin.AccessTokenFile != "") {
+		return errors.New("github: Anonymous mode does not support access tokens")
+	}
+	return nil
+}
+
+// anonymousBudgetExhausted reports whether the remaining unauthenticated rate limit is too low to gather another repo
+// (after the given number of repos has been gathered during the current gather). If the gather interval is known, every
+// gather is only granted its share of the budget left until the rate limit reset, so the budget is spread across the
+// rate limit window instead of being used up by the first gathers.
+func (plugin *GitHub) anonymousBudgetExhausted(processed int, now time.Time) bool {
+	if !plugin.Anonymous || plugin.rateLimitUsage == nil {
+		return false
+	}
+	if plugin.rateLimitUsage.exhausted("core", anonymousRepoRequests) {
+		return true
+	}
+	rate, known := plugin.rateLimitUsage.lastLimits()["core"]
+	if !known || plugin.gatherInterval <= 0 {
+		return false
+	}
+	gathersLeft := int((rate.Reset.Sub(now) + plugin.gatherInterval - 1) / plugin.gatherInterval)
+	if gathersLeft < 1 {
+		gathersLeft = 1
+	}
+	used := processed * anonymousRepoRequests
+	share := (rate.Remaining + used) / gathersLeft
+	return used+anonymousRepoRequests > share
+}
+
+// rotateAnonymousRepos rotates the repos to start with the first repo skipped during the previous gather, so all repos
+// get their turn even if the budget does not suffice to gather all of them at once.
+func (plugin *GitHub) rotateAnonymousRepos(repos []string) []string {
+	if !plugin.Anonymous || len(repos) == 0 {
+		return repos
+	}
+	offset := plugin.anonymousOffset % len(repos)
+	return append(append(make([]string, 0, len(repos)), repos[offset:]...), repos[:offset]...)
+}
+
+// advanceAnonymousRepos records the number of repos gathered, so the next gather continues with the first skipped one.
+func (plugin *GitHub) advanceAnonymousRepos(repos int, processed int) {
+	if plugin.Anonymous && repos > 0 {
+		plugin.anonymousOffset = (plugin.anonymousOffset + processed) % repos
+	}
+}
@@ -0,0 +1,292 @@
+// apps.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// appInstallation represents a GitHub App installation. The permissions are decoded generically, to also cover
+// permissions not yet known to the go-github API version in use.
+type appInstallation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+	} `json:"account"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// jwtTransport authenticates requests as the GitHub App itself (required for the /app endpoints).
+type jwtTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *jwtTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	jwt, err := transport.plugin.currentAppJWT()
+	if err != nil {
+		return nil, err
+	}
+	authorizedRequest := request.Clone(request.Context())
+	authorizedRequest.Header.Set("Authorization", "Bearer "+jwt)
+	return transport.base.RoundTrip(authorizedRequest)
+}
+
+// appJWTValidity is the validity of the created app JWTs (GitHub accepts at most 10 minutes).
+const appJWTValidity = 9 * time.Minute
+
+// appJWTRefresh is the remaining validity below which the app JWT is re-created.
+const appJWTRefresh = time.Minute
+
+// appJWT is the JWT currently used to authenticate as the GitHub App itself together with the parsed private key the
+// JWT is signed with.
+type appJWT struct {
+	mutex      sync.Mutex
+	privateKey *rsa.PrivateKey
+	jwt        string
+	expiresAt  time.Time
+}
+
+// installationTokenRefresh is the remaining validity below which an installation token is refreshed.
+const installationTokenRefresh = 5 * time.Minute
+
+// installationToken is the installation access token currently used to authenticate as the GitHub App installation.
+type installationToken struct {
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// installationTransport authenticates requests as the GitHub App installation. The installation token is created on
+// demand and refreshed shortly before it expires (installation tokens are only valid for one hour).
+type installationTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *installationTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := transport.plugin.currentInstallationToken(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	authorizedRequest := request.Clone(request.Context())
+	authorizedRequest.Header.Set("Authorization", "token "+token)
+	return transport.base.RoundTrip(authorizedRequest)
+}
+
+// appAuthentication reports whether to authenticate as the configured GitHub App installation (only done if no access
+// tokens are configured).
+func (plugin *GitHub) appAuthentication() bool {
+	return plugin.AccessToken.Empty() && len(plugin.AccessTokens) == 0 && plugin.AccessTokenFile == "" && !plugin.Anonymous && plugin.AppID != 0 && plugin.InstallationID != 0 && (plugin.PrivateKey != "" || plugin.PrivateKeyPath != "")
+}
+
+// authenticated reports whether requests are authenticated (either via access token or as GitHub App installation).
+func (plugin *GitHub) authenticated() bool {
+	return !plugin.AccessToken.Empty() || len(plugin.AccessTokens) > 0 || plugin.AccessTokenFile != "" || plugin.appAuthentication()
+}
+
+func (plugin *GitHub) currentInstallationToken(ctx context.Context) (string, error) {
+	state := &plugin.installationToken
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.token != "" && time.Until(state.expiresAt) > installationTokenRefresh {
+		return state.token, nil
+	}
+	if plugin.Debug {
+		plugin.Log.Debugf("Creating installation token for app installation %d...", plugin.InstallationID)
+	}
+	appClient, err := plugin.getAppClient()
+	if err != nil {
+		return "", err
+	}
+	request, err := appClient.NewRequest("POST", fmt.Sprintf("app/installations/%d/access_tokens", plugin.InstallationID), nil)
+	if err != nil {
+		return "", err
+	}
+	token := &githubApi.InstallationToken{}
+	_, err = appClient.Do(ctx, request, token)
+	if err != nil {
+		return "", err
+	}
+	if token.GetToken() == "" {
+		return "", fmt.Errorf("github: No installation token received for app installation %d", plugin.InstallationID)
+	}
+	state.token = token.GetToken()
+	state.expiresAt = token.GetExpiresAt()
+	return state.token, nil
+}
+
+func (plugin *GitHub) getAppClient() (*githubApi.Client, error) {
+	if plugin.appClient == nil {
+		return nil, errors.New("github: Missing app_id or private_key/private_key_path")
+	}
+	return plugin.appClient, nil
+}
+
+// createAppClient parses the configured private key and creates the client authenticating as the GitHub App itself
+// (nil, if no app is configured).
+func (plugin *GitHub) createAppClient() (*githubApi.Client, error) {
+	if plugin.AppID == 0 || (plugin.PrivateKey == "" && plugin.PrivateKeyPath == "") {
+		return nil, nil
+	}
+	privateKey, err := plugin.readPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	plugin.appJWT.privateKey = privateKey
+	httpClient := &http.Client{
+		Transport: &jwtTransport{base: plugin.newTransport(), plugin: plugin},
+		Timeout:   time.Duration(plugin.Timeout) * time.Second,
+	}
+	return plugin.newAPIClient(httpClient)
+}
+
+// currentAppJWT returns the current app JWT, which is re-created shortly before it expires.
+func (plugin *GitHub) currentAppJWT() (string, error) {
+	state := &plugin.appJWT
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	now := time.Now()
+	if state.jwt != "" && state.expiresAt.Sub(now) > appJWTRefresh {
+		return state.jwt, nil
+	}
+	expiresAt := now.Add(appJWTValidity)
+	jwt, err := plugin.createAppJWT(now, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	state.jwt = jwt
+	state.expiresAt = expiresAt
+	return jwt, nil
+}
+
+func (plugin *GitHub) createAppJWT(now time.Time, expiresAt time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		// backdate issue time to allow for clock drift
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": expiresAt.Unix(),
+		"iss": strconv.FormatInt(plugin.AppID, 10),
+	}
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, plugin.appJWT.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (plugin *GitHub) readPrivateKey() (*rsa.PrivateKey, error) {
+	pemBytes := []byte(plugin.PrivateKey)
+	keySource := "private_key"
+	if plugin.PrivateKey == "" {
+		var err error
+		pemBytes, err = os.ReadFile(plugin.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		keySource = plugin.PrivateKeyPath
+	}
+	pemBlock, _ := pem.Decode(pemBytes)
+	if pemBlock == nil {
+		return nil, fmt.Errorf("github: No PEM data found in private key '%s'", keySource)
+	}
+	privateKey, err := x509.ParsePKCS1PrivateKey(pemBlock.Bytes)
+	if err == nil {
+		return privateKey, nil
+	}
+	pkcs8Key, err := x509.ParsePKCS8PrivateKey(pemBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := pkcs8Key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("github: Private key '%s' does not contain a RSA key", keySource)
+	}
+	return rsaKey, nil
+}
+
+func (plugin *GitHub) processAppPermissions(ctx context.Context, a telegraf.Accumulator) error {
+	appClient, err := plugin.getAppClient()
+	if err != nil {
+		return err
+	}
+	request, err := appClient.NewRequest("GET", fmt.Sprintf("app/installations/%d", plugin.InstallationID), nil)
+	if err != nil {
+		return err
+	}
+	installation := &appInstallation{}
+	_, err = appClient.Do(ctx, request, installation)
+	if err != nil {
+		return err
+	}
+	driftedPermissions := plugin.appPermissionsDrift(installation.Permissions)
+	if len(driftedPermissions) > 0 {
+		plugin.Log.Warnf("Permissions of app installation %d differ from expectation: %v", plugin.InstallationID, driftedPermissions)
+	}
+	tags := make(map[string]string)
+	tags["github_org"] = installation.Account.Login
+	tags["installation_id"] = strconv.FormatInt(plugin.InstallationID, 10)
+	fields := make(map[string]interface{})
+	for permission, access := range installation.Permissions {
+		fields["permission_"+permission] = access
+	}
+	fields["permissions"] = len(installation.Permissions)
+	fields["drifted_permissions"] = len(driftedPermissions)
+	fields["drift"] = len(driftedPermissions) > 0
+	a.AddCounter("github_app_permissions", fields, tags)
+	return nil
+}
+
+// appPermissionsDrift returns the sorted names of all permissions whose granted access differs from the expected one.
+func (plugin *GitHub) appPermissionsDrift(granted map[string]string) []string {
+	drifted := make([]string, 0)
+	if len(plugin.ExpectedAppPermissions) == 0 {
+		return drifted
+	}
+	for permission, expectedAccess := range plugin.ExpectedAppPermissions {
+		if granted[permission] != expectedAccess {
+			drifted = append(drifted, permission)
+		}
+	}
+	for permission := range granted {
+		if _, expected := plugin.ExpectedAppPermissions[permission]; !expected {
+			drifted = append(drifted, permission)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted
+}
@@ -0,0 +1,111 @@
+// assettypes.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"mime"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const assetTypeArchive = "archive"
+const assetTypeInstaller = "installer"
+const assetTypePackage = "package"
+const assetTypeChecksum = "checksum"
+const assetTypeSignature = "signature"
+const assetTypeOther = "other"
+
+// assetTypes lists all asset types an asset is classified as (limiting the number of emitted series per repo).
+var assetTypes = []string{assetTypeArchive, assetTypeInstaller, assetTypePackage, assetTypeChecksum, assetTypeSignature, assetTypeOther}
+
+// assetNamePatterns classifies assets by their file name. These patterns take precedence over the content type, as
+// checksums and signatures are commonly uploaded with a generic content type.
+var assetNamePatterns = []struct {
+	assetType string
+	patterns  []string
+}{
+	{assetType: assetTypeChecksum, patterns: []string{"*.sha1", "*.sha256", "*.sha512", "*.md5", "*checksums*", "*sha256sum*", "*sha512sum*"}},
+	{assetType: assetTypeSignature, patterns: []string{"*.sig", "*.asc", "*.pem", "*.sigstore", "*.sigstore.json"}},
+	{assetType: assetTypeInstaller, patterns: []string{"*.msi", "*.exe", "*.dmg", "*.pkg", "*.appimage", "*.apk"}},
+	{assetType: assetTypePackage, patterns: []string{"*.deb", "*.rpm", "*.snap", "*.flatpak", "*.nupkg", "*.whl", "*.jar"}},
+	{assetType: assetTypeArchive, patterns: []string{"*.zip", "*.tar", "*.tar.gz", "*.tgz", "*.tar.xz", "*.txz", "*.tar.bz2", "*.tbz2", "*.tar.zst", "*.gz", "*.xz", "*.bz2", "*.zst", "*.7z"}},
+}
+
+// assetContentTypes classifies assets not matching any of the name patterns by their content type.
+var assetContentTypes = map[string]string{
+	"application/zip":                               assetTypeArchive,
+	"application/x-zip-compressed":                  assetTypeArchive,
+	"application/gzip":                              assetTypeArchive,
+	"application/x-gzip":                            assetTypeArchive,
+	"application/x-tar":                             assetTypeArchive,
+	"application/x-gtar":                            assetTypeArchive,
+	"application/x-xz":                              assetTypeArchive,
+	"application/x-bzip2":                           assetTypeArchive,
+	"application/x-7z-compressed":                   assetTypeArchive,
+	"application/zstd":                              assetTypeArchive,
+	"application/x-msdownload":                      assetTypeInstaller,
+	"application/x-msi":                             assetTypeInstaller,
+	"application/x-ms-installer":                    assetTypeInstaller,
+	"application/x-apple-diskimage":                 assetTypeInstaller,
+	"application/vnd.microsoft.portable-executable": assetTypeInstaller,
+	"application/vnd.debian.binary-package":         assetTypePackage,
+	"application/x-debian-package":                  assetTypePackage,
+	"application/x-rpm":                             assetTypePackage,
+	"application/x-redhat-package-manager":          assetTypePackage,
+	"application/java-archive":                      assetTypePackage,
+	"application/pgp-signature":                     assetTypeSignature,
+}
+
+// processReleaseAssetTypes sums up the download counts of the counted releases' assets per asset type. Unlike the total
+// download count, all assets are considered (regardless of the asset include/exclude patterns), as the breakdown is
+// meant to show e.g. the share of checksum downloads.
+func (plugin *GitHub) processReleaseAssetTypes(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease) {
+	downloadCounts := make(map[string]int)
+	assetCounts := make(map[string]int)
+	for _, repoRelease := range repoReleases {
+		if !plugin.countRelease(repoRelease) {
+			continue
+		}
+		for _, asset := range repoRelease.Assets {
+			assetType := releaseAssetType(asset.GetName(), asset.GetContentType())
+			downloadCounts[assetType] += asset.GetDownloadCount()
+			assetCounts[assetType]++
+		}
+	}
+	for _, assetType := range assetTypes {
+		if assetCounts[assetType] == 0 {
+			continue
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["asset_type"] = assetType
+		fields := make(map[string]interface{})
+		fields["download_count"] = downloadCounts[assetType]
+		fields["assets"] = assetCounts[assetType]
+		a.AddCounter("github_release_asset_types", fields, tags)
+	}
+}
+
+// releaseAssetType classifies an asset by its name and content type.
+func releaseAssetType(name string, contentType string) string {
+	for _, namePatterns := range assetNamePatterns {
+		if matchAssetName(name, namePatterns.patterns) {
+			return namePatterns.assetType
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		assetType, known := assetContentTypes[strings.ToLower(mediaType)]
+		if known {
+			return assetType
+		}
+	}
+	return assetTypeOther
+}
@@ -0,0 +1,47 @@
+// auditlog.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processAuditLogSummary(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	phrase := "created:>=" + time.Now().Add(-plugin.auditLogWindow).UTC().Format("2006-01-02T15:04:05Z")
+	include := "all"
+	opts := &githubApi.GetAuditLogOptions{Phrase: &phrase, Include: &include, ListCursorOptions: githubApi.ListCursorOptions{PerPage: 100}}
+	actionCounts := make(map[string]int)
+	for {
+		auditEntries, response, err := client.Organizations.GetAuditLog(ctx, org, opts)
+		if err != nil {
+			return err
+		}
+		for _, auditEntry := range auditEntries {
+			actionCounts[auditEntry.GetAction()]++
+		}
+		if response.After == "" || len(auditEntries) == 0 {
+			break
+		}
+		opts.After = response.After
+	}
+	for action, count := range actionCounts {
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["category"], _, _ = strings.Cut(action, ".")
+		tags["action"] = action
+		fields := make(map[string]interface{})
+		fields["events"] = count
+		a.AddCounter("github_audit_log", fields, tags)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// auth.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+var accessTokenNames = []string{"access_token", "backup_access_token"}
+
+type tokenFailover struct {
+	previous int
+	current  int
+}
+
+// tokenState tracks the currently active access token across gathers as well as the failovers of the current gather.
+type tokenState struct {
+	mutex     sync.Mutex
+	active    int
+	failovers []tokenFailover
+}
+
+func (plugin *GitHub) checkBackupAccessToken() error {
+	if !plugin.BackupAccessToken.Empty() && plugin.AccessToken.Empty() {
+		return errors.New("github: backup_access_token requires access_token")
+	}
+	return nil
+}
+
+// resolveAccessTokens determines the configured access tokens used for authentication: either the rotated access tokens
+// or the access token followed by the backup access token. The tokens (which may be stored in a secret store) are only
+// checked for being resolvable here; they are resolved again for every request to not keep them in memory in plaintext.
+func (plugin *GitHub) resolveAccessTokens() error {
+	secrets := []*config.Secret{&plugin.AccessToken, &plugin.BackupAccessToken}
+	if len(plugin.AccessTokens) > 0 {
+		secrets = make([]*config.Secret, 0, len(plugin.AccessTokens))
+		for i := range plugin.AccessTokens {
+			secrets = append(secrets, &plugin.AccessTokens[i])
+		}
+	}
+	tokens := make([]*config.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret.Empty() {
+			continue
+		}
+		token, err := secret.Get()
+		if err != nil {
+			return fmt.Errorf("github: Failed to resolve access token: %v", err)
+		}
+		token.Destroy()
+		tokens = append(tokens, secret)
+	}
+	plugin.tokens = tokens
+	return nil
+}
+
+func (plugin *GitHub) accessTokens() []*config.Secret {
+	return plugin.tokens
+}
+
+// authorizeRequest authenticates the given request with the given access token, which is resolved for this request only.
+func authorizeRequest(request *http.Request, token *config.Secret) error {
+	resolvedToken, err := token.Get()
+	if err != nil {
+		return fmt.Errorf("github: Failed to resolve access token: %v", err)
+	}
+	defer resolvedToken.Destroy()
+	request.Header.Set("Authorization", "Bearer "+resolvedToken.String())
+	return nil
+}
+
+func (state *tokenState) current() int {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.active
+}
+
+// failover switches to the next access token unless another request already did so in the meantime.
+func (state *tokenState) failover(failed int, tokens int) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.active != failed {
+		return
+	}
+	state.active = (failed + 1) % tokens
+	state.failovers = append(state.failovers, tokenFailover{previous: failed, current: state.active})
+}
+
+func (state *tokenState) takeFailovers() []tokenFailover {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	failovers := state.failovers
+	state.failovers = nil
+	return failovers
+}
+
+// failoverTransport authenticates requests with the active access token and fails over to the next configured token
+// (retrying the request) if the active one is rejected with 401 (e.g. because it has been revoked or is expired). With a
+// single access token configured, requests are simply authenticated with it.
+type failoverTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *failoverTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	tokens := transport.plugin.accessTokens()
+	retryable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		active := transport.plugin.tokenState.current()
+		authRequest := request.Clone(request.Context())
+		if attempt > 0 && request.Body != nil && request.Body != http.NoBody {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			authRequest.Body = body
+		}
+		err := authorizeRequest(authRequest, tokens[active])
+		if err != nil {
+			return nil, err
+		}
+		response, err := transport.base.RoundTrip(authRequest)
+		if err != nil || response.StatusCode != http.StatusUnauthorized || attempt >= len(tokens)-1 || !retryable {
+			return response, err
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		transport.plugin.Log.Warnf("Access token '%s' has been rejected; failing over to '%s'", accessTokenNames[active], accessTokenNames[(active+1)%len(tokens)])
+		transport.plugin.tokenState.failover(active, len(tokens))
+	}
+}
+
+func (plugin *GitHub) processTokenFailovers(a telegraf.Accumulator) {
+	for _, failover := range plugin.tokenState.takeFailovers() {
+		tags := make(map[string]string)
+		tags["event"] = "token_failover"
+		fields := make(map[string]interface{})
+		fields["previous"] = accessTokenNames[failover.previous]
+		fields["current"] = accessTokenNames[failover.current]
+		a.AddFields("github_auth_event", fields, tags)
+	}
+}
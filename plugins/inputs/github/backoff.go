@@ -0,0 +1,76 @@
+// backoff.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxBackoffLevel limits the back-off to skipping 63 consecutive gathers.
+const maxBackoffLevel = 6
+
+// backoffState tracks server side failures (5xx responses as well as primary and secondary rate limit responses)
+// and derives the number of gathers to skip from them. Every gather seeing failures doubles the effective polling
+// interval, the first gather without failures resets it.
+type backoffState struct {
+	mutex     sync.Mutex
+	level     int
+	skip      int
+	incidents int
+}
+
+func isIncidentResponse(response *http.Response) bool {
+	if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return response.StatusCode == http.StatusForbidden && (response.Header.Get("Retry-After") != "" || response.Header.Get("X-RateLimit-Remaining") == "0")
+}
+
+func (state *backoffState) update(response *http.Response) {
+	if !isIncidentResponse(response) {
+		return
+	}
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.incidents++
+}
+
+// skipGather reports whether the current gather has to be skipped due to an active back-off.
+func (state *backoffState) skipGather() bool {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.skip > 0 {
+		state.skip--
+		return true
+	}
+	state.incidents = 0
+	return false
+}
+
+// complete evaluates the failures of the finished gather and returns the resulting back-off level.
+func (state *backoffState) complete() int {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.incidents > 0 {
+		if state.level < maxBackoffLevel {
+			state.level++
+		}
+		state.skip = 1<<state.level - 1
+	} else {
+		state.level = 0
+	}
+	state.incidents = 0
+	return state.level
+}
+
+func (state *backoffState) currentLevel() int {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.level
+}
@@ -0,0 +1,258 @@
+// batch.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+)
+
+const graphQLBatchRepoFragment = `fragment repoFields on Repository {
+  nameWithOwner
+  owner {
+    __typename
+    login
+  }
+  isPrivate
+  visibility
+  defaultBranchRef {
+    name
+  }
+  createdAt
+  diskUsage
+  stargazerCount
+  forkCount
+  watchers {
+    totalCount
+  }
+  viewerPermission
+  releases(first: 100, orderBy: {field: CREATED_AT, direction: DESC}) @include(if: $releases) {
+    pageInfo {
+      hasNextPage
+    }
+    nodes {
+      databaseId
+      tagName
+      name
+      description
+      isDraft
+      isPrerelease
+      createdAt
+      publishedAt
+      releaseAssets(first: 100) {
+        pageInfo {
+          hasNextPage
+        }
+        nodes {
+          name
+          contentType
+          size
+          downloadCount
+          createdAt
+          updatedAt
+        }
+      }
+    }
+  }
+}`
+
+type graphQLBatchRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	Owner         struct {
+		Typename string `json:"__typename"`
+		Login    string `json:"login"`
+	} `json:"owner"`
+	IsPrivate        bool   `json:"isPrivate"`
+	Visibility       string `json:"visibility"`
+	DefaultBranchRef *struct {
+		Name string `json:"name"`
+	} `json:"defaultBranchRef"`
+	CreatedAt      time.Time `json:"createdAt"`
+	DiskUsage      int       `json:"diskUsage"`
+	StargazerCount int       `json:"stargazerCount"`
+	ForkCount      int       `json:"forkCount"`
+	Watchers       struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"watchers"`
+	ViewerPermission string `json:"viewerPermission"`
+	Releases         *struct {
+		PageInfo struct {
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"pageInfo"`
+		Nodes []struct {
+			DatabaseID    int64      `json:"databaseId"`
+			TagName       string     `json:"tagName"`
+			Name          string     `json:"name"`
+			Description   string     `json:"description"`
+			IsDraft       bool       `json:"isDraft"`
+			IsPrerelease  bool       `json:"isPrerelease"`
+			CreatedAt     time.Time  `json:"createdAt"`
+			PublishedAt   *time.Time `json:"publishedAt"`
+			ReleaseAssets struct {
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					Name          string    `json:"name"`
+					ContentType   string    `json:"contentType"`
+					Size          int       `json:"size"`
+					DownloadCount int       `json:"downloadCount"`
+					CreatedAt     time.Time `json:"createdAt"`
+					UpdatedAt     time.Time `json:"updatedAt"`
+				} `json:"nodes"`
+			} `json:"releaseAssets"`
+		} `json:"nodes"`
+	} `json:"releases"`
+}
+
+// prefetchedRepo holds the repo info and (if complete) the releases of a repo fetched in a GraphQL batch.
+type prefetchedRepo struct {
+	info     *githubApi.Repository
+	releases []*githubApi.RepositoryRelease
+}
+
+// graphQLBatchEnabled reports whether the repo info and releases are fetched via GraphQL batches (which requires
+// authentication and is only supported by GitHub itself).
+func (plugin *GitHub) graphQLBatchEnabled() bool {
+	return plugin.GraphQLBatch && !plugin.isGitea() && !plugin.Anonymous && plugin.authenticated()
+}
+
+// prefetchRepos fetches the repo info and releases of the given repos in batches of the configured size via GraphQL.
+// Instead of the two (or more) REST requests per repo, this requires only a single request per batch. Repos missing
+// from a batch response (e.g. because they are not accessible) and releases exceeding a single page (respectively
+// releases with more than 100 assets) are not prefetched; they are gathered via REST as usual.
+func (plugin *GitHub) prefetchRepos(ctx context.Context, client *githubApi.Client, repos []string) {
+	prefetched := make(map[string]*prefetchedRepo)
+	batchSize := plugin.GraphQLBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	for start := 0; start < len(repos); start += batchSize {
+		end := start + batchSize
+		if end > len(repos) {
+			end = len(repos)
+		}
+		err := plugin.prefetchRepoBatch(ctx, client, repos[start:end], prefetched)
+		if err != nil {
+			plugin.Log.Warnf("Falling back to REST for %d repos due to failed GraphQL batch: %v", end-start, err)
+		}
+	}
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	plugin.prefetchedRepos = prefetched
+}
+
+func (plugin *GitHub) prefetchRepoBatch(ctx context.Context, client *githubApi.Client, repos []string, prefetched map[string]*prefetchedRepo) error {
+	var query strings.Builder
+	query.WriteString("query($releases: Boolean!) {\n")
+	for i, repo := range repos {
+		repoOwner, repoName, err := plugin.splitRepoId(repo)
+		if err != nil {
+			return err
+		}
+		quotedOwner, _ := json.Marshal(repoOwner)
+		quotedName, _ := json.Marshal(repoName)
+		fmt.Fprintf(&query, "  r%d: repository(owner: %s, name: %s) {\n    ...repoFields\n  }\n", i, quotedOwner, quotedName)
+	}
+	query.WriteString("}\n")
+	query.WriteString(graphQLBatchRepoFragment)
+	// release digests require the assets' ids not available via GraphQL
+	releases := plugin.collectorEnabled(collectorReleases) && !plugin.ReleaseDigests
+	result := make(map[string]*graphQLBatchRepository)
+	messages, err := plugin.queryGraphQLPartial(ctx, client, query.String(), map[string]interface{}{"releases": releases}, &result)
+	if err != nil {
+		return err
+	}
+	if len(messages) > 0 && plugin.Debug {
+		plugin.Log.Debugf("GraphQL batch reported errors: %s", strings.Join(messages, "; "))
+	}
+	for i, repo := range repos {
+		batchRepo := result[fmt.Sprintf("r%d", i)]
+		if batchRepo == nil {
+			continue
+		}
+		prefetched[repo] = &prefetchedRepo{info: batchRepo.repository(), releases: batchRepo.repositoryReleases()}
+	}
+	return nil
+}
+
+// repository converts the GraphQL repo info into the REST API's representation.
+func (batchRepo *graphQLBatchRepository) repository() *githubApi.Repository {
+	defaultBranch := ""
+	if batchRepo.DefaultBranchRef != nil {
+		defaultBranch = batchRepo.DefaultBranchRef.Name
+	}
+	push := batchRepo.ViewerPermission == "ADMIN" || batchRepo.ViewerPermission == "MAINTAIN" || batchRepo.ViewerPermission == "WRITE"
+	return &githubApi.Repository{
+		FullName:         githubApi.String(batchRepo.NameWithOwner),
+		Owner:            &githubApi.User{Login: githubApi.String(batchRepo.Owner.Login), Type: githubApi.String(batchRepo.Owner.Typename)},
+		Private:          githubApi.Bool(batchRepo.IsPrivate),
+		Visibility:       githubApi.String(strings.ToLower(batchRepo.Visibility)),
+		DefaultBranch:    githubApi.String(defaultBranch),
+		CreatedAt:        &githubApi.Timestamp{Time: batchRepo.CreatedAt},
+		Size:             githubApi.Int(batchRepo.DiskUsage),
+		StargazersCount:  githubApi.Int(batchRepo.StargazerCount),
+		ForksCount:       githubApi.Int(batchRepo.ForkCount),
+		SubscribersCount: githubApi.Int(batchRepo.Watchers.TotalCount),
+		Permissions:      map[string]bool{"push": push},
+	}
+}
+
+// repositoryReleases converts the GraphQL releases into the REST API's representation. Nil is returned if the releases
+// have not been queried or are incomplete. As GraphQL does not provide the assets' ids, the asset ids are left unset.
+func (batchRepo *graphQLBatchRepository) repositoryReleases() []*githubApi.RepositoryRelease {
+	if batchRepo.Releases == nil || batchRepo.Releases.PageInfo.HasNextPage {
+		return nil
+	}
+	releases := make([]*githubApi.RepositoryRelease, 0, len(batchRepo.Releases.Nodes))
+	for _, node := range batchRepo.Releases.Nodes {
+		if node.ReleaseAssets.PageInfo.HasNextPage {
+			return nil
+		}
+		release := &githubApi.RepositoryRelease{
+			ID:         githubApi.Int64(node.DatabaseID),
+			TagName:    githubApi.String(node.TagName),
+			Name:       githubApi.String(node.Name),
+			Body:       githubApi.String(node.Description),
+			Draft:      githubApi.Bool(node.IsDraft),
+			Prerelease: githubApi.Bool(node.IsPrerelease),
+			CreatedAt:  &githubApi.Timestamp{Time: node.CreatedAt},
+			Assets:     make([]*githubApi.ReleaseAsset, 0, len(node.ReleaseAssets.Nodes)),
+		}
+		if node.PublishedAt != nil {
+			release.PublishedAt = &githubApi.Timestamp{Time: *node.PublishedAt}
+		}
+		for _, assetNode := range node.ReleaseAssets.Nodes {
+			release.Assets = append(release.Assets, &githubApi.ReleaseAsset{
+				Name:          githubApi.String(assetNode.Name),
+				ContentType:   githubApi.String(assetNode.ContentType),
+				Size:          githubApi.Int(assetNode.Size),
+				DownloadCount: githubApi.Int(assetNode.DownloadCount),
+				CreatedAt:     &githubApi.Timestamp{Time: assetNode.CreatedAt},
+				UpdatedAt:     &githubApi.Timestamp{Time: assetNode.UpdatedAt},
+			})
+		}
+		releases = append(releases, release)
+	}
+	return releases
+}
+
+// takePrefetchedRepo returns (and forgets) the prefetched data of the given repo (nil if the repo has not been
+// prefetched).
+func (plugin *GitHub) takePrefetchedRepo(repo string) *prefetchedRepo {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	prefetched := plugin.prefetchedRepos[repo]
+	delete(plugin.prefetchedRepos, repo)
+	return prefetched
+}
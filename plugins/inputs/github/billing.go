@@ -0,0 +1,186 @@
+// billing.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// billingUsage represents the usage report of the enhanced billing platform (not yet covered by the go-github API version in use).
+type billingUsage struct {
+	UsageItems []*billingUsageItem `json:"usageItems"`
+}
+
+type billingUsageItem struct {
+	Date           string  `json:"date"`
+	Product        string  `json:"product"`
+	SKU            string  `json:"sku"`
+	Quantity       float64 `json:"quantity"`
+	UnitType       string  `json:"unitType"`
+	NetAmount      float64 `json:"netAmount"`
+	RepositoryName string  `json:"repositoryName"`
+}
+
+type actionsUsageKey struct {
+	repo       string
+	runnerType string
+}
+
+type actionsUsage struct {
+	minutes   float64
+	netAmount float64
+}
+
+// getBillingUsage fetches the usage items of the current month. It is fetched once per org and gather and shared by
+// all billing based collectors.
+func (plugin *GitHub) getBillingUsage(ctx context.Context, client *githubApi.Client, org string) (*billingUsage, error) {
+	now := time.Now().UTC()
+	request, err := client.NewRequest("GET", fmt.Sprintf("organizations/%s/settings/billing/usage?year=%d&month=%d", org, now.Year(), int(now.Month())), nil)
+	if err != nil {
+		return nil, err
+	}
+	usage := &billingUsage{}
+	_, err = client.Do(ctx, request, usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (plugin *GitHub) processActionsUsage(a telegraf.Accumulator, org string, usage *billingUsage) {
+	repoUsages := make(map[actionsUsageKey]*actionsUsage)
+	for _, usageItem := range usage.UsageItems {
+		if !strings.EqualFold(usageItem.Product, "actions") || !strings.EqualFold(usageItem.UnitType, "minutes") {
+			continue
+		}
+		key := actionsUsageKey{repo: usageItem.RepositoryName, runnerType: usageItem.SKU}
+		repoUsage := repoUsages[key]
+		if repoUsage == nil {
+			repoUsage = &actionsUsage{}
+			repoUsages[key] = repoUsage
+		}
+		repoUsage.minutes += usageItem.Quantity
+		repoUsage.netAmount += usageItem.NetAmount
+	}
+	for key, repoUsage := range repoUsages {
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		if key.repo != "" {
+			tags["github_repo"] = billingRepo(org, key.repo)
+		}
+		tags["runner_type"] = key.runnerType
+		fields := make(map[string]interface{})
+		fields["minutes"] = repoUsage.minutes
+		fields["net_amount"] = repoUsage.netAmount
+		a.AddCounter("github_actions_usage", fields, tags)
+	}
+}
+
+// processActionsLeaderboard ranks the org's repos by their Actions minutes (summed up across all runner types) and reports
+// the top repos together with their share of the org's total minutes (the share is omitted if no minutes have been used
+// at all).
+func (plugin *GitHub) processActionsLeaderboard(a telegraf.Accumulator, org string, usage *billingUsage) {
+	repoUsages := make(map[string]*actionsUsage)
+	totalMinutes := 0.0
+	for _, usageItem := range usage.UsageItems {
+		if !strings.EqualFold(usageItem.Product, "actions") || !strings.EqualFold(usageItem.UnitType, "minutes") {
+			continue
+		}
+		totalMinutes += usageItem.Quantity
+		if usageItem.RepositoryName == "" {
+			continue
+		}
+		repo := billingRepo(org, usageItem.RepositoryName)
+		repoUsage := repoUsages[repo]
+		if repoUsage == nil {
+			repoUsage = &actionsUsage{}
+			repoUsages[repo] = repoUsage
+		}
+		repoUsage.minutes += usageItem.Quantity
+		repoUsage.netAmount += usageItem.NetAmount
+	}
+	repos := make([]string, 0, len(repoUsages))
+	for repo := range repoUsages {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repoUsages[repos[i]].minutes != repoUsages[repos[j]].minutes {
+			return repoUsages[repos[i]].minutes > repoUsages[repos[j]].minutes
+		}
+		return repos[i] < repos[j]
+	})
+	if len(repos) > plugin.ActionsLeaderboard {
+		repos = repos[:plugin.ActionsLeaderboard]
+	}
+	for i, repo := range repos {
+		repoUsage := repoUsages[repo]
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["github_repo"] = repo
+		fields := make(map[string]interface{})
+		fields["rank"] = i + 1
+		fields["minutes"] = repoUsage.minutes
+		fields["net_amount"] = repoUsage.netAmount
+		if totalMinutes > 0 {
+			fields["minutes_share_percent"] = repoUsage.minutes * 100.0 / totalMinutes
+		}
+		a.AddCounter("github_actions_leaderboard", fields, tags)
+	}
+}
+
+// billingRepo qualifies the repo names reported by the billing platform (which may lack the org) with the org.
+func billingRepo(org string, repo string) string {
+	if !strings.Contains(repo, "/") {
+		return org + "/" + repo
+	}
+	return repo
+}
+
+func (plugin *GitHub) processLFSUsage(a telegraf.Accumulator, org string, usage *billingUsage) {
+	now := time.Now().UTC()
+	monthHours := now.Sub(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)).Hours()
+	storageGB := 0.0
+	bandwidthGB := 0.0
+	for _, usageItem := range usage.UsageItems {
+		if !strings.Contains(strings.ToLower(usageItem.Product), "lfs") {
+			continue
+		}
+		quantity := usageItem.Quantity
+		// storage is billed in GB hours; convert it to the average GB stored during the month so far
+		if strings.EqualFold(usageItem.UnitType, "GigabyteHours") && monthHours > 0 {
+			quantity = quantity / monthHours
+		}
+		sku := strings.ToLower(usageItem.SKU)
+		if strings.Contains(sku, "storage") {
+			storageGB += quantity
+		} else if strings.Contains(sku, "bandwidth") {
+			bandwidthGB += quantity
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	fields := make(map[string]interface{})
+	fields["storage_gb"] = storageGB
+	fields["bandwidth_gb"] = bandwidthGB
+	fields["storage_quota_gb"] = plugin.LFSStorageQuota
+	fields["bandwidth_quota_gb"] = plugin.LFSBandwidthQuota
+	if plugin.LFSStorageQuota > 0 {
+		fields["storage_used_percent"] = storageGB * 100.0 / plugin.LFSStorageQuota
+	}
+	if plugin.LFSBandwidthQuota > 0 {
+		fields["bandwidth_used_percent"] = bandwidthGB * 100.0 / plugin.LFSBandwidthQuota
+	}
+	a.AddCounter("github_lfs_usage", fields, tags)
+}
@@ -0,0 +1,147 @@
+// cache.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// cachedResponse is a response persisted in the cache directory together with the validators required to revalidate it.
+type cachedResponse struct {
+	URL          string
+	ETag         string
+	LastModified string
+	Header       http.Header
+	Body         []byte
+}
+
+// cachingTransport issues conditional requests for all GET requests with a cached response and serves the cached
+// response if GitHub reports it as not modified. As GitHub does not count not modified responses against the rate
+// limit, the cache saves most of the rate limit budget for rarely changing resources. The cache is kept on disk and
+// therefore survives restarts.
+type cachingTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *cachingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != http.MethodGet {
+		return transport.base.RoundTrip(request)
+	}
+	cachePath := transport.cachePath(request)
+	cached := transport.readCachedResponse(cachePath)
+	if cached != nil {
+		conditionalRequest := request.Clone(request.Context())
+		if cached.ETag != "" {
+			conditionalRequest.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			conditionalRequest.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+		request = conditionalRequest
+	}
+	response, err := transport.base.RoundTrip(request)
+	if err != nil {
+		return response, err
+	}
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		if transport.plugin.Debug {
+			transport.plugin.Log.Debugf("Using cached response for '%s'...", cached.URL)
+		}
+		return transport.cachedResponse(response, cached), nil
+	}
+	if response.StatusCode == http.StatusOK && (response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != "") {
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		transport.writeCachedResponse(cachePath, &cachedResponse{
+			URL:          request.URL.String(),
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+			Header:       response.Header,
+			Body:         body,
+		})
+	}
+	return response, nil
+}
+
+// cachedResponse turns a not modified response into the cached response. The headers of the not modified response take
+// precedence, to keep the rate limit tracking up to date.
+func (transport *cachingTransport) cachedResponse(notModified *http.Response, cached *cachedResponse) *http.Response {
+	notModified.Body.Close()
+	header := cached.Header.Clone()
+	for key, values := range notModified.Header {
+		header[key] = values
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       notModified.Request,
+	}
+}
+
+func (transport *cachingTransport) cachePath(request *http.Request) string {
+	hash := sha256.Sum256([]byte(request.Header.Get("Accept") + " " + request.URL.String()))
+	return filepath.Join(transport.plugin.CacheDir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (transport *cachingTransport) readCachedResponse(cachePath string) *cachedResponse {
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil
+	}
+	cached := &cachedResponse{}
+	err = json.Unmarshal(data, cached)
+	if err != nil {
+		transport.plugin.Log.Warnf("Ignoring invalid cache file '%s': %v", cachePath, err)
+		return nil
+	}
+	return cached
+}
+
+// writeCachedResponse persists a response via a temporary file, so concurrent readers never see a partially written file.
+func (transport *cachingTransport) writeCachedResponse(cachePath string, cached *cachedResponse) {
+	data, err := json.Marshal(cached)
+	if err == nil {
+		var tempFile *os.File
+		tempFile, err = os.CreateTemp(filepath.Dir(cachePath), "*.tmp")
+		if err == nil {
+			_, err = tempFile.Write(data)
+			closeErr := tempFile.Close()
+			if err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				err = os.Rename(tempFile.Name(), cachePath)
+			}
+			if err != nil {
+				os.Remove(tempFile.Name())
+			}
+		}
+	}
+	if err != nil {
+		transport.plugin.Log.Warnf("Failed to write cache file '%s': %v", cachePath, err)
+	}
+}
@@ -0,0 +1,61 @@
+// circuit.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"sync"
+)
+
+// repoCircuit tracks the consecutive failing gathers of a repo and the number of gathers still to skip.
+type repoCircuit struct {
+	failures int
+	skip     int
+}
+
+// circuitBreaker skips repos failing for a number of consecutive gathers (e.g. deleted repos or repos the token lacks
+// access to) for a number of gathers. Once the skipped gathers are over, a single further failure re-opens the circuit.
+type circuitBreaker struct {
+	mutex    sync.Mutex
+	circuits map[string]*repoCircuit
+}
+
+// allow reports whether the given repo is to be gathered. If not, the remaining number of gathers to skip is returned.
+func (breaker *circuitBreaker) allow(repo string) (bool, int) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	circuit := breaker.circuits[repo]
+	if circuit == nil || circuit.skip == 0 {
+		return true, 0
+	}
+	circuit.skip--
+	return false, circuit.skip
+}
+
+// record records the outcome of a repo gather and reports whether the failure opened the repo's circuit.
+func (breaker *circuitBreaker) record(repo string, err error, failures int, skip int) bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	if err == nil {
+		delete(breaker.circuits, repo)
+		return false
+	}
+	if breaker.circuits == nil {
+		breaker.circuits = make(map[string]*repoCircuit)
+	}
+	circuit := breaker.circuits[repo]
+	if circuit == nil {
+		circuit = &repoCircuit{}
+		breaker.circuits[repo] = circuit
+	}
+	circuit.failures++
+	if circuit.failures < failures {
+		return false
+	}
+	circuit.skip = skip
+	return true
+}
@@ -0,0 +1,158 @@
+// codeowners.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// codeownersPaths lists the locations GitHub looks for the CODEOWNERS file in (in order).
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// processCodeowners cross-references the CODEOWNERS owners with the org's members respectively teams and counts the
+// open pull requests waiting for a review of an owner no longer available.
+func (plugin *GitHub) processCodeowners(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, openPullRequests []*githubApi.PullRequest) error {
+	codeowners, err := plugin.getCodeowners(ctx, client, repoOwner, repoName)
+	if err != nil {
+		return err
+	}
+	owners := parseCodeowners(codeowners)
+	unavailableOwners := make(map[string]bool)
+	for _, owner := range owners {
+		available, err := plugin.isCodeownerAvailable(ctx, client, repoOwner, owner)
+		if err != nil {
+			return err
+		}
+		if !available {
+			unavailableOwners[owner] = true
+		}
+	}
+	blockedPullRequests := 0
+	for _, pullRequest := range openPullRequests {
+		for _, reviewer := range requestedCodeowners(pullRequest, repoOwner) {
+			if unavailableOwners[reviewer] {
+				blockedPullRequests++
+				break
+			}
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["has_codeowners"] = codeowners != ""
+	fields["codeowners"] = len(owners)
+	fields["unavailable_codeowners"] = len(unavailableOwners)
+	fields["pull_requests_with_unavailable_reviewers"] = blockedPullRequests
+	a.AddCounter("github_codeowners", fields, tags)
+	return nil
+}
+
+func (plugin *GitHub) getCodeowners(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string) (string, error) {
+	for _, codeownersPath := range codeownersPaths {
+		codeownersFile, _, response, err := client.Repositories.GetContents(ctx, repoOwner, repoName, codeownersPath, nil)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return "", err
+		}
+		if codeownersFile == nil {
+			continue
+		}
+		return codeownersFile.GetContent()
+	}
+	return "", nil
+}
+
+// parseCodeowners collects the distinct user ("@user") and team ("@org/team") owners (in lower case). Email owners
+// are ignored as they cannot be checked against the org.
+func parseCodeowners(codeowners string) []string {
+	owners := make([]string, 0)
+	known := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(codeowners))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		entries := strings.Fields(line)
+		if len(entries) < 2 {
+			continue
+		}
+		for _, owner := range entries[1:] {
+			owner = strings.ToLower(owner)
+			if strings.HasPrefix(owner, "@") && !known[owner] {
+				known[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// codeownerCheck holds the result of a code owner's availability check until the discovery interval has elapsed.
+type codeownerCheck struct {
+	available bool
+	checkedAt time.Time
+}
+
+// isCodeownerAvailable checks whether the given owner is (still) a member respectively team of the org. As the owners are
+// shared by most of an org's repos, the results are remembered for the discovery interval.
+func (plugin *GitHub) isCodeownerAvailable(ctx context.Context, client *githubApi.Client, org string, owner string) (bool, error) {
+	key := org + ":" + owner
+	now := time.Now()
+	plugin.stateMutex.Lock()
+	check := plugin.codeownerChecks[key]
+	plugin.stateMutex.Unlock()
+	if check != nil && now.Sub(check.checkedAt) < plugin.discoveryInterval {
+		return check.available, nil
+	}
+	available, err := plugin.checkCodeownerAvailable(ctx, client, org, owner)
+	if err != nil {
+		return false, err
+	}
+	plugin.stateMutex.Lock()
+	plugin.codeownerChecks[key] = &codeownerCheck{available: available, checkedAt: now}
+	plugin.stateMutex.Unlock()
+	return available, nil
+}
+
+func (plugin *GitHub) checkCodeownerAvailable(ctx context.Context, client *githubApi.Client, org string, owner string) (bool, error) {
+	teamOrg, teamSlug, found := strings.Cut(owner[1:], "/")
+	if found {
+		_, response, err := client.Teams.GetTeamBySlug(ctx, teamOrg, teamSlug)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	member, _, err := client.Organizations.IsMember(ctx, org, owner[1:])
+	return member, err
+}
+
+// requestedCodeowners lists the pull request's requested reviewers in CODEOWNERS notation.
+func requestedCodeowners(pullRequest *githubApi.PullRequest, org string) []string {
+	reviewers := make([]string, 0)
+	for _, user := range pullRequest.RequestedReviewers {
+		reviewers = append(reviewers, "@"+strings.ToLower(user.GetLogin()))
+	}
+	for _, team := range pullRequest.RequestedTeams {
+		reviewers = append(reviewers, "@"+strings.ToLower(org+"/"+team.GetSlug()))
+	}
+	return reviewers
+}
@@ -0,0 +1,74 @@
+// collectors.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"fmt"
+	"time"
+)
+
+// collectorIntervalSlack tolerates gathers being triggered slightly early, which would otherwise delay a collector by a
+// full plugin interval.
+const collectorIntervalSlack = time.Minute
+
+const (
+	collectorInfo     = "info"
+	collectorReleases = "releases"
+	collectorTraffic  = "traffic"
+)
+
+func (plugin *GitHub) checkCollectors() error {
+	for _, collector := range plugin.Collectors {
+		switch collector {
+		case collectorInfo, collectorReleases, collectorTraffic:
+		default:
+			return fmt.Errorf("github: Invalid collector '%s'", collector)
+		}
+	}
+	intervals := make(map[string]time.Duration)
+	for collector, interval := range map[string]string{collectorReleases: plugin.ReleasesInterval, collectorTraffic: plugin.TrafficInterval, collectorStargazerLocations: plugin.StargazerLocationsInterval} {
+		if interval == "" {
+			continue
+		}
+		duration, err := parseWindow(interval)
+		if err != nil {
+			return fmt.Errorf("github: Invalid %s interval '%s'", collector, interval)
+		}
+		intervals[collector] = duration
+	}
+	plugin.collectorIntervals = intervals
+	return nil
+}
+
+func (plugin *GitHub) collectorEnabled(collector string) bool {
+	for _, enabled := range plugin.Collectors {
+		if enabled == collector {
+			return true
+		}
+	}
+	return false
+}
+
+// collectorDue reports whether the given collector's interval has passed since its last successful run for the given repo.
+func (plugin *GitHub) collectorDue(repo string, collector string, now time.Time) bool {
+	interval := plugin.collectorIntervals[collector]
+	if interval == 0 {
+		return true
+	}
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	lastRun, known := plugin.collectorRuns[repo+":"+collector]
+	return !known || now.Sub(lastRun)+collectorIntervalSlack >= interval
+}
+
+// collectorDone records a successful run of the given collector for the given repo.
+func (plugin *GitHub) collectorDone(repo string, collector string, now time.Time) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	plugin.collectorRuns[repo+":"+collector] = now
+}
@@ -0,0 +1,145 @@
+// copilot.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// copilotMetricsDay represents a day of the Copilot metrics API (not yet covered by the go-github API version in use).
+type copilotMetricsDay struct {
+	Date                      string                     `json:"date"`
+	TotalActiveUsers          int                        `json:"total_active_users"`
+	TotalEngagedUsers         int                        `json:"total_engaged_users"`
+	CopilotIDECodeCompletions *copilotIDECodeCompletions `json:"copilot_ide_code_completions"`
+}
+
+type copilotIDECodeCompletions struct {
+	TotalEngagedUsers int `json:"total_engaged_users"`
+	Languages         []*struct {
+		Name              string `json:"name"`
+		TotalEngagedUsers int    `json:"total_engaged_users"`
+	} `json:"languages"`
+	Editors []*struct {
+		Name              string `json:"name"`
+		TotalEngagedUsers int    `json:"total_engaged_users"`
+		Models            []*struct {
+			Name      string                    `json:"name"`
+			Languages []*copilotLanguageMetrics `json:"languages"`
+		} `json:"models"`
+	} `json:"editors"`
+}
+
+type copilotLanguageMetrics struct {
+	Name                    string `json:"name"`
+	TotalEngagedUsers       int    `json:"total_engaged_users"`
+	TotalCodeSuggestions    int    `json:"total_code_suggestions"`
+	TotalCodeAcceptances    int    `json:"total_code_acceptances"`
+	TotalCodeLinesSuggested int    `json:"total_code_lines_suggested"`
+	TotalCodeLinesAccepted  int    `json:"total_code_lines_accepted"`
+}
+
+// copilotCompletions sums up the code completion figures of a breakdown.
+type copilotCompletions struct {
+	suggestions    int
+	acceptances    int
+	linesSuggested int
+	linesAccepted  int
+}
+
+func (completions *copilotCompletions) add(languageMetrics *copilotLanguageMetrics) {
+	completions.suggestions += languageMetrics.TotalCodeSuggestions
+	completions.acceptances += languageMetrics.TotalCodeAcceptances
+	completions.linesSuggested += languageMetrics.TotalCodeLinesSuggested
+	completions.linesAccepted += languageMetrics.TotalCodeLinesAccepted
+}
+
+func (completions *copilotCompletions) addFields(fields map[string]interface{}) {
+	fields["suggestions"] = completions.suggestions
+	fields["acceptances"] = completions.acceptances
+	fields["lines_suggested"] = completions.linesSuggested
+	fields["lines_accepted"] = completions.linesAccepted
+}
+
+// processCopilotUsage reports the daily Copilot code completion metrics of the given org. Besides the org total
+// (breakdown=total), the metrics are broken down per language (breakdown=language) and per editor (breakdown=editor).
+// The API reports the completion figures per editor, model and language, hence the breakdowns are summed up
+// accordingly.
+func (plugin *GitHub) processCopilotUsage(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	request, err := client.NewRequest("GET", fmt.Sprintf("orgs/%s/copilot/metrics", org), nil)
+	if err != nil {
+		return err
+	}
+	var metricsDays []*copilotMetricsDay
+	_, err = client.Do(ctx, request, &metricsDays)
+	if err != nil {
+		return err
+	}
+	for _, metricsDay := range metricsDays {
+		day, err := time.Parse("2006-01-02", metricsDay.Date)
+		if err != nil {
+			return err
+		}
+		total := &copilotCompletions{}
+		languageCompletions := make(map[string]*copilotCompletions)
+		languageEngagedUsers := make(map[string]int)
+		languageNames := make([]string, 0)
+		completions := metricsDay.CopilotIDECodeCompletions
+		if completions != nil {
+			for _, language := range completions.Languages {
+				languageEngagedUsers[language.Name] = language.TotalEngagedUsers
+			}
+			for _, editor := range completions.Editors {
+				editorCompletions := &copilotCompletions{}
+				for _, model := range editor.Models {
+					for _, languageMetrics := range model.Languages {
+						editorCompletions.add(languageMetrics)
+						total.add(languageMetrics)
+						if languageCompletions[languageMetrics.Name] == nil {
+							languageCompletions[languageMetrics.Name] = &copilotCompletions{}
+							languageNames = append(languageNames, languageMetrics.Name)
+						}
+						languageCompletions[languageMetrics.Name].add(languageMetrics)
+					}
+				}
+				editorTags := make(map[string]string)
+				editorTags["github_org"] = org
+				editorTags["breakdown"] = "editor"
+				editorTags["editor"] = editor.Name
+				editorFields := make(map[string]interface{})
+				editorFields["engaged_users"] = editor.TotalEngagedUsers
+				editorCompletions.addFields(editorFields)
+				a.AddCounter("github_copilot_usage", editorFields, editorTags, day)
+			}
+		}
+		for _, languageName := range languageNames {
+			languageTags := make(map[string]string)
+			languageTags["github_org"] = org
+			languageTags["breakdown"] = "language"
+			languageTags["language"] = languageName
+			languageFields := make(map[string]interface{})
+			languageFields["engaged_users"] = languageEngagedUsers[languageName]
+			languageCompletions[languageName].addFields(languageFields)
+			a.AddCounter("github_copilot_usage", languageFields, languageTags, day)
+		}
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["breakdown"] = "total"
+		fields := make(map[string]interface{})
+		fields["active_users"] = metricsDay.TotalActiveUsers
+		fields["engaged_users"] = metricsDay.TotalEngagedUsers
+		total.addFields(fields)
+		a.AddCounter("github_copilot_usage", fields, tags, day)
+	}
+	return nil
+}
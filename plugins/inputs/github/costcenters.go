@@ -0,0 +1,136 @@
+// costcenters.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+type costCenterPattern struct {
+	pattern    string
+	costCenter string
+}
+
+type costCenterMapping struct {
+	repos    map[string]string
+	patterns []costCenterPattern
+}
+
+func (plugin *GitHub) resolveCostCenters(ctx context.Context, client *githubApi.Client) (*costCenterMapping, error) {
+	mapping := &costCenterMapping{repos: make(map[string]string)}
+	costCenters := make([]string, 0, len(plugin.CostCenters))
+	for costCenter := range plugin.CostCenters {
+		costCenters = append(costCenters, costCenter)
+	}
+	sort.Strings(costCenters)
+	for _, costCenter := range costCenters {
+		for _, entry := range plugin.CostCenters[costCenter] {
+			if !strings.HasPrefix(entry, "@") {
+				mapping.patterns = append(mapping.patterns, costCenterPattern{pattern: strings.ToLower(entry), costCenter: costCenter})
+				continue
+			}
+			teamOrg, teamSlug, err := plugin.splitTeamId(entry[1:])
+			if err != nil {
+				return nil, err
+			}
+			opts := &githubApi.ListOptions{PerPage: 100}
+			for {
+				teamRepos, response, err := client.Teams.ListTeamReposBySlug(ctx, teamOrg, teamSlug, opts)
+				if err != nil {
+					return nil, err
+				}
+				for _, teamRepo := range teamRepos {
+					if _, mapped := mapping.repos[strings.ToLower(teamRepo.GetFullName())]; !mapped {
+						mapping.repos[strings.ToLower(teamRepo.GetFullName())] = costCenter
+					}
+				}
+				if response.NextPage == 0 {
+					break
+				}
+				opts.Page = response.NextPage
+			}
+		}
+	}
+	return mapping, nil
+}
+
+func (plugin *GitHub) splitTeamId(team string) (string, string, error) {
+	teamParts := strings.Split(team, "/")
+	if len(teamParts) != 2 {
+		return "", "", fmt.Errorf("github: Invalid team identifier '%s'", team)
+	}
+	return teamParts[0], teamParts[1], nil
+}
+
+func (mapping *costCenterMapping) costCenter(repo string) string {
+	lowerRepo := strings.ToLower(repo)
+	for _, pattern := range mapping.patterns {
+		matched, _ := path.Match(pattern.pattern, lowerRepo)
+		if matched {
+			return pattern.costCenter
+		}
+	}
+	return mapping.repos[lowerRepo]
+}
+
+// costCenterAccumulator attaches the cost_center tag to all metrics carrying a mapped github_repo tag.
+type costCenterAccumulator struct {
+	telegraf.Accumulator
+	mapping *costCenterMapping
+}
+
+func (acc *costCenterAccumulator) tag(tags map[string]string) map[string]string {
+	repo, ok := tags["github_repo"]
+	if !ok {
+		return tags
+	}
+	costCenter := acc.mapping.costCenter(repo)
+	if costCenter != "" {
+		tags["cost_center"] = costCenter
+	}
+	return tags
+}
+
+func (acc *costCenterAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddFields(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *costCenterAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddGauge(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *costCenterAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddCounter(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *costCenterAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddSummary(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *costCenterAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddHistogram(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *costCenterAccumulator) AddMetric(metric telegraf.Metric) {
+	repo, ok := metric.GetTag("github_repo")
+	if ok {
+		costCenter := acc.mapping.costCenter(repo)
+		if costCenter != "" {
+			metric.AddTag("cost_center", costCenter)
+		}
+	}
+	acc.Accumulator.AddMetric(metric)
+}
@@ -0,0 +1,162 @@
+// cron.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed 5 field cron expression as used by GitHub workflow schedules (always UTC).
+type cronSchedule struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+	anyDay   bool
+	anyWday  bool
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("github: Invalid cron expression '%s'", expr)
+	}
+	schedule := &cronSchedule{}
+	var err error
+	schedule.minutes, err = parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	schedule.hours, err = parseCronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	schedule.days, err = parseCronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return nil, err
+	}
+	schedule.months, err = parseCronField(fields[3], 1, 12, cronMonthNames)
+	if err != nil {
+		return nil, err
+	}
+	schedule.weekdays, err = parseCronField(fields[4], 0, 7, cronWeekdayNames)
+	if err != nil {
+		return nil, err
+	}
+	// 7 is an alias for sunday
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	schedule.anyDay = fields[2] == "*"
+	schedule.anyWday = fields[4] == "*"
+	return schedule, nil
+}
+
+func parseCronField(field string, low int, high int, names map[string]int) ([]bool, error) {
+	values := make([]bool, high+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		rangePart := part
+		if stepIndex := strings.Index(part, "/"); stepIndex >= 0 {
+			parsedStep, err := strconv.Atoi(part[stepIndex+1:])
+			if err != nil || parsedStep <= 0 {
+				return nil, fmt.Errorf("github: Invalid cron step '%s'", part)
+			}
+			step = parsedStep
+			rangePart = part[:stepIndex]
+		}
+		first := low
+		last := high
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			first, err = parseCronValue(bounds[0], low, high, names)
+			if err != nil {
+				return nil, err
+			}
+			last = first
+			if len(bounds) == 2 {
+				last, err = parseCronValue(bounds[1], low, high, names)
+				if err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				last = high
+			}
+		}
+		if first > last {
+			return nil, fmt.Errorf("github: Invalid cron range '%s'", part)
+		}
+		for value := first; value <= last; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+func parseCronValue(value string, low int, high int, names map[string]int) (int, error) {
+	if names != nil {
+		named, ok := names[strings.ToUpper(value)]
+		if ok {
+			return named, nil
+		}
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < low || parsed > high {
+		return 0, fmt.Errorf("github: Invalid cron value '%s'", value)
+	}
+	return parsed, nil
+}
+
+func (schedule *cronSchedule) matchesDay(t time.Time) bool {
+	dayMatch := schedule.days[t.Day()]
+	wdayMatch := schedule.weekdays[int(t.Weekday())]
+	if schedule.anyDay || schedule.anyWday {
+		return dayMatch && wdayMatch
+	}
+	return dayMatch || wdayMatch
+}
+
+// next returns the first scheduled time strictly after the given time (or the zero time if there is none within 5 years).
+func (schedule *cronSchedule) next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !schedule.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+			continue
+		}
+		if !schedule.hours[t.Hour()] {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !schedule.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
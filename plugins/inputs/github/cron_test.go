@@ -0,0 +1,44 @@
+// cron_test.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	after := time.Date(2022, 10, 21, 12, 0, 0, 0, time.UTC) // friday
+
+	daily, err := parseCronSchedule("0 2 * * *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 10, 22, 2, 0, 0, 0, time.UTC), daily.next(after))
+
+	workdays, err := parseCronSchedule("30 4 * * MON-FRI")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 10, 24, 4, 30, 0, 0, time.UTC), workdays.next(after))
+
+	quarterly, err := parseCronSchedule("*/15 0 1 1,4,7,10 *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), quarterly.next(after))
+
+	never, err := parseCronSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	require.True(t, never.next(after).IsZero())
+}
+
+func TestCronScheduleInvalid(t *testing.T) {
+	_, err := parseCronSchedule("0 2 * *")
+	require.Error(t, err)
+	_, err = parseCronSchedule("61 2 * * *")
+	require.Error(t, err)
+	_, err = parseCronSchedule("0 2 * * FOO")
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+// deployments.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+type deploymentStats struct {
+	deployments      int
+	failures         int
+	finished         int
+	durationsSeconds float64
+}
+
+func (plugin *GitHub) processDeployments(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	windowStart := time.Now().Add(-plugin.window)
+	environmentStats := make(map[string]*deploymentStats)
+	opts := &githubApi.DeploymentsListOptions{ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		deployments, response, err := client.Repositories.ListDeployments(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return err
+		}
+		windowReached := false
+		for _, deployment := range deployments {
+			if deployment.GetCreatedAt().Before(windowStart) {
+				windowReached = true
+				break
+			}
+			stats := environmentStats[deployment.GetEnvironment()]
+			if stats == nil {
+				stats = &deploymentStats{}
+				environmentStats[deployment.GetEnvironment()] = stats
+			}
+			stats.deployments++
+			err = plugin.evalDeploymentStatuses(ctx, client, repoOwner, repoName, deployment, stats)
+			if err != nil {
+				return err
+			}
+		}
+		if windowReached || response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	for environment, stats := range environmentStats {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_environment"] = environment
+		fields := make(map[string]interface{})
+		fields["deployments"] = stats.deployments
+		fields["failed_deployments"] = stats.failures
+		fields["failure_percent"] = 0.0
+		fields["avg_duration_seconds"] = 0.0
+		if stats.finished > 0 {
+			fields["failure_percent"] = float64(stats.failures) * 100.0 / float64(stats.finished)
+			fields["avg_duration_seconds"] = stats.durationsSeconds / float64(stats.finished)
+		}
+		a.AddCounter("github_deployment", fields, tags)
+	}
+	return nil
+}
+
+func (plugin *GitHub) evalDeploymentStatuses(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, deployment *githubApi.Deployment, stats *deploymentStats) error {
+	statuses, _, err := client.Repositories.ListDeploymentStatuses(ctx, repoOwner, repoName, deployment.GetID(), &githubApi.ListOptions{PerPage: 100})
+	if err != nil {
+		return err
+	}
+	// statuses are listed newest first; the first terminal status determines the deployment outcome
+	for _, status := range statuses {
+		state := status.GetState()
+		if state != "success" && state != "failure" && state != "error" {
+			continue
+		}
+		stats.finished++
+		if state != "success" {
+			stats.failures++
+		}
+		stats.durationsSeconds += status.GetCreatedAt().Sub(deployment.GetCreatedAt().Time).Seconds()
+		break
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// discovery.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const defaultDiscoveryInterval = "1d"
+
+// discoveredRepos holds the repos discovered for an org or user until the discovery interval has elapsed.
+type discoveredRepos struct {
+	repos        []string
+	discoveredAt time.Time
+}
+
+// repoChurn holds the changes of an org's repos between the last two discoveries.
+type repoChurn struct {
+	repos   int
+	created int
+	removed int
+}
+
+// isRepoPattern reports whether the given repos entry is a glob pattern (e.g. "myorg/*" or "myorg/terraform-*").
+func isRepoPattern(entry string) bool {
+	return strings.ContainsAny(entry, "*?[")
+}
+
+// splitRepoPattern splits a repo pattern into the org to discover the repos of and the repo name pattern.
+func splitRepoPattern(entry string) (string, string, error) {
+	patternParts := strings.Split(entry, "/")
+	if len(patternParts) != 2 || isRepoPattern(patternParts[0]) || !validPattern(patternParts[1]) {
+		return "", "", fmt.Errorf("github: Invalid repo pattern '%s'", entry)
+	}
+	return patternParts[0], patternParts[1], nil
+}
+
+// resolveRepos expands the glob pattern entries (e.g. "<org>/*") of the configured repos (including the ones listed in
+// the repos file) to the matching org repos and adds the repos of the configured topics and users. Repos matching any of the exclude patterns are dropped. The resulting list
+// contains every repo only once, in the order of the configured entries.
+func (plugin *GitHub) resolveRepos(ctx context.Context, client *githubApi.Client) ([]string, error) {
+	repos := make([]string, 0, len(plugin.Repos))
+	known := make(map[string]bool)
+	addRepos := func(addedRepos ...string) {
+		for _, repo := range addedRepos {
+			if !known[repo] && !matchRepo(repo, plugin.ReposExclude) {
+				known[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	entries := plugin.Repos
+	if plugin.ReposFile != "" {
+		fileEntries, err := plugin.readReposFile()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(append(make([]string, 0, len(entries)+len(fileEntries)), entries...), fileEntries...)
+	}
+	for _, entry := range entries {
+		if !isRepoPattern(entry) {
+			addRepos(entry)
+			continue
+		}
+		org, namePattern, err := splitRepoPattern(entry)
+		if err != nil {
+			return nil, err
+		}
+		orgRepos := plugin.discoverReposOrFallback(ctx, "org:"+org, func() ([]string, error) {
+			return plugin.listOrgRepos(ctx, client, org)
+		})
+		for _, orgRepo := range orgRepos {
+			if matchRepo(path.Base(orgRepo), []string{namePattern}) {
+				addRepos(orgRepo)
+			}
+		}
+	}
+	for _, topic := range plugin.Topics {
+		topicRepos := plugin.discoverReposOrFallback(ctx, "topic:"+topic, func() ([]string, error) {
+			return plugin.searchTopicRepos(ctx, client, topic)
+		})
+		addRepos(topicRepos...)
+	}
+	for _, user := range plugin.Users {
+		userRepos := plugin.discoverReposOrFallback(ctx, "user:"+user, func() ([]string, error) {
+			return plugin.listUserRepos(ctx, client, user)
+		})
+		addRepos(userRepos...)
+	}
+	return repos, nil
+}
+
+// readReposFile reads the repos file (one repo or repo pattern per line). Blank lines and comments (starting with #) are
+// ignored; invalid entries are logged and skipped.
+func (plugin *GitHub) readReposFile() ([]string, error) {
+	file, err := os.Open(plugin.ReposFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		entry := scanner.Text()
+		if comment := strings.Index(entry, "#"); comment >= 0 {
+			entry = entry[:comment]
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		entryParts := strings.Split(entry, "/")
+		if len(entryParts) != 2 || entryParts[0] == "" || entryParts[1] == "" {
+			plugin.Log.Errorf("Ignoring invalid repo '%s' in line %d of repos file %s", entry, lineNumber, plugin.ReposFile)
+			continue
+		}
+		if isRepoPattern(entry) {
+			_, _, err := splitRepoPattern(entry)
+			if err != nil {
+				plugin.Log.Errorf("Ignoring invalid repo pattern '%s' in line %d of repos file %s", entry, lineNumber, plugin.ReposFile)
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// discoverRepos returns the previously discovered repos for the given key or runs the given discovery if the discovery
+// interval has elapsed since.
+func (plugin *GitHub) discoverRepos(ctx context.Context, key string, discover func() ([]string, error)) ([]string, error) {
+	now := time.Now()
+	cached := plugin.discoveredRepos[key]
+	if cached != nil && now.Sub(cached.discoveredAt) < plugin.discoveryInterval {
+		return cached.repos, nil
+	}
+	repos, err := discover()
+	if err != nil {
+		return nil, err
+	}
+	if plugin.Debug {
+		plugin.Log.Infof("Discovered %d repos for %s", len(repos), key)
+	}
+	if plugin.RepoChurn && cached != nil && strings.HasPrefix(key, "org:") {
+		plugin.repoChurns[strings.TrimPrefix(key, "org:")] = diffDiscoveredRepos(cached.repos, repos)
+	}
+	plugin.discoveredRepos[key] = &discoveredRepos{repos: repos, discoveredAt: now}
+	return repos, nil
+}
+
+// discoverReposOrFallback runs discoverRepos for the given key. A failed discovery does not abort the gather: the error is
+// logged and the repos discovered previously for the key (if any) are used until the discovery succeeds again.
+func (plugin *GitHub) discoverReposOrFallback(ctx context.Context, key string, discover func() ([]string, error)) []string {
+	repos, err := plugin.discoverRepos(ctx, key, discover)
+	if err != nil {
+		var fallback []string
+		if cached := plugin.discoveredRepos[key]; cached != nil {
+			fallback = cached.repos
+		}
+		plugin.Log.Errorf("Failed to discover repos for %s (using %d previously discovered repos): %v", key, len(fallback), err)
+		return fallback
+	}
+	return repos
+}
+
+// diffDiscoveredRepos counts the repos created and removed between two discoveries. As archived repos are not
+// discovered (unless include_archived is set), repos archived in the meantime count as removed.
+func diffDiscoveredRepos(previous []string, current []string) *repoChurn {
+	previousRepos := make(map[string]bool, len(previous))
+	for _, repo := range previous {
+		previousRepos[repo] = true
+	}
+	churn := &repoChurn{repos: len(current)}
+	for _, repo := range current {
+		if previousRepos[repo] {
+			delete(previousRepos, repo)
+		} else {
+			churn.created++
+		}
+	}
+	churn.removed = len(previousRepos)
+	return churn
+}
+
+// processRepoChurns emits the repo churn of the orgs rediscovered during this gather.
+func (plugin *GitHub) processRepoChurns(a telegraf.Accumulator) {
+	for org, churn := range plugin.repoChurns {
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		fields := make(map[string]interface{})
+		fields["repos"] = churn.repos
+		fields["created_repos"] = churn.created
+		fields["removed_repos"] = churn.removed
+		a.AddCounter("github_repo_churn", fields, tags)
+		delete(plugin.repoChurns, org)
+	}
+}
+
+func (plugin *GitHub) listOrgRepos(ctx context.Context, client *githubApi.Client, org string) ([]string, error) {
+	repos := make([]string, 0)
+	opts := &githubApi.RepositoryListByOrgOptions{Type: "all", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		orgRepos, response, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, err
+		}
+		repos = plugin.appendDiscoveredRepos(repos, orgRepos)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return repos, nil
+}
+
+func (plugin *GitHub) listUserRepos(ctx context.Context, client *githubApi.Client, user string) ([]string, error) {
+	repos := make([]string, 0)
+	opts := &githubApi.RepositoryListOptions{Type: "owner", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		userRepos, response, err := client.Repositories.List(ctx, user, opts)
+		if err != nil {
+			return nil, err
+		}
+		repos = plugin.appendDiscoveredRepos(repos, userRepos)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return repos, nil
+}
+
+// searchTopicRepos searches the repos tagged with the given topic (optionally restricted to the configured org). Note
+// that the search API reports at most 1000 repos.
+func (plugin *GitHub) searchTopicRepos(ctx context.Context, client *githubApi.Client, topic string) ([]string, error) {
+	query := "topic:" + topic
+	if plugin.TopicsOrg != "" {
+		query += " org:" + plugin.TopicsOrg
+	}
+	repos := make([]string, 0)
+	opts := &githubApi.SearchOptions{ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		result, response, err := client.Search.Repositories(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		repos = plugin.appendDiscoveredRepos(repos, result.Repositories)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return repos, nil
+}
+
+func (plugin *GitHub) appendDiscoveredRepos(repos []string, discovered []*githubApi.Repository) []string {
+	for _, repo := range discovered {
+		if (repo.GetFork() && !plugin.IncludeForks) || (repo.GetArchived() && !plugin.IncludeArchived) {
+			continue
+		}
+		repos = append(repos, repo.GetFullName())
+	}
+	return repos
+}
+
+// validPattern checks whether the given glob pattern is well-formed (malformed patterns never match, hence they are
+// rejected up front instead of silently matching nothing).
+func validPattern(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// checkPatterns validates all configured glob patterns (the repos file's entries are validated on every read, invalid
+// ones are logged and skipped).
+func (plugin *GitHub) checkPatterns() error {
+	for _, entry := range plugin.Repos {
+		if isRepoPattern(entry) {
+			_, _, err := splitRepoPattern(entry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	patternOptions := []struct {
+		option   string
+		patterns []string
+	}{
+		{option: "repos_exclude", patterns: plugin.ReposExclude},
+		{option: "asset_include", patterns: plugin.AssetInclude},
+		{option: "asset_exclude", patterns: plugin.AssetExclude},
+		{option: "bot_assets", patterns: plugin.BotAssets},
+		{option: "signature_patterns", patterns: plugin.SignaturePatterns},
+		{option: "sbom_patterns", patterns: plugin.SBOMPatterns},
+		{option: "provenance_patterns", patterns: plugin.ProvenancePatterns},
+	}
+	for _, patternOption := range patternOptions {
+		for _, pattern := range patternOption.patterns {
+			if !validPattern(pattern) {
+				return fmt.Errorf("github: Invalid %s pattern '%s'", patternOption.option, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func matchRepo(repo string, patterns []string) bool {
+	lowerRepo := strings.ToLower(repo)
+	for _, pattern := range patterns {
+		matched, _ := path.Match(strings.ToLower(pattern), lowerRepo)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
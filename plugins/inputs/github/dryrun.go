@@ -0,0 +1,247 @@
+// dryrun.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// plannedCall describes an API call issued during a gather. Calls with an empty per attribute are issued exactly once,
+// all other calls are issued once per listed item (e.g. per workflow) and therefore depend on the actual repo content.
+type plannedCall struct {
+	endpoint string
+	per      string
+}
+
+func (plugin *GitHub) planRepoCalls(repo string) []plannedCall {
+	calls := make([]plannedCall, 0)
+	batched := plugin.graphQLBatchEnabled()
+	if !batched {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo})
+	}
+	if plugin.collectorEnabled(collectorReleases) {
+		if !batched || plugin.ReleaseDigests {
+			calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/releases"})
+		}
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/releases", per: "additional page"})
+		if plugin.TagCoverage {
+			calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/tags"})
+		}
+		for _, branch := range plugin.MaintenanceBranches {
+			calls = append(calls,
+				plannedCall{endpoint: fmt.Sprintf("GET /repos/%s/compare/<release>...%s", repo, branch)},
+				plannedCall{endpoint: fmt.Sprintf("GET /repos/%s/compare/<release>...%s", repo, branch), per: "newly checked release"})
+		}
+	}
+	if plugin.authenticated() && plugin.collectorEnabled(collectorTraffic) {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/traffic/views"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/traffic/clones"})
+	}
+	if plugin.Referrers {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/traffic/popular/referrers"})
+	}
+	if plugin.PopularPaths {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/traffic/popular/paths"})
+	}
+	if plugin.WorkflowSchedules {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/actions/workflows"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/contents/<workflow path>", per: "workflow"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/actions/workflows/<id>/runs", per: "scheduled workflow"})
+	}
+	if plugin.WorkflowConcurrency || plugin.WorkflowReruns {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/actions/runs"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/actions/runs", per: "additional page"})
+	}
+	if plugin.Environments {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/environments"})
+	}
+	if plugin.Deployments {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/deployments"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/deployments", per: "additional page"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/deployments/<id>/statuses", per: "deployment"})
+	}
+	if plugin.TagProtection {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/rulesets"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/rulesets/<id>", per: "active tag ruleset"})
+	}
+	if plugin.recentIssuesRequired() {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/issues"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/issues", per: "additional page"})
+	}
+	if plugin.IssueTriage {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/issues/<number>/events", per: "open or newly closed issue"})
+	}
+	if plugin.ClassicProjects {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/projects"})
+	}
+	if plugin.Rulesets {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/rulesets"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/rulesets/<id>", per: "ruleset"})
+	}
+	if plugin.Submodules {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/contents/.gitmodules"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/contents/<submodule path>", per: "submodule"},
+			plannedCall{endpoint: "GET /repos/<upstream>", per: "submodule"},
+			plannedCall{endpoint: "GET /repos/<upstream>/compare/<pinned>...<default branch>", per: "submodule"})
+	}
+	if plugin.Activity {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/issues/comments"})
+	}
+	if plugin.DependencyPullRequests || plugin.PullRequestBranches || plugin.Codeowners {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/pulls"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/pulls", per: "additional page"})
+	}
+	if plugin.MergeConflicts {
+		calls = append(calls,
+			plannedCall{endpoint: "POST /graphql (pullRequests)"},
+			plannedCall{endpoint: "POST /graphql (pullRequests)", per: "additional 100 open pull requests"})
+	}
+	if plugin.Codeowners {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/contents/.github/CODEOWNERS"},
+			plannedCall{endpoint: "GET /orgs/" + strings.Split(repo, "/")[0] + "/members/<user>", per: "code owner not checked within the discovery interval"})
+	}
+	if len(plugin.ActivityPaths) > 0 {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/pulls/<number>/files", per: "open or newly closed pull request"})
+		for _, path := range plugin.ActivityPaths {
+			calls = append(calls, plannedCall{endpoint: fmt.Sprintf("GET /repos/%s/commits?path=%s", repo, path)})
+		}
+	}
+	if plugin.ForkConversion {
+		calls = append(calls, plannedCall{endpoint: "GET /repos/" + repo + "/forks"})
+	}
+	if plugin.StargazerLocations {
+		calls = append(calls,
+			plannedCall{endpoint: "POST /graphql (stargazers)"},
+			plannedCall{endpoint: "POST /graphql (stargazers)", per: "additional sample page"})
+	}
+	if plugin.StatusContexts {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /repos/" + repo + "/commits"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/commits/<sha>/status", per: "inspected commit"},
+			plannedCall{endpoint: "GET /repos/" + repo + "/commits/<sha>/check-runs", per: "inspected commit"})
+	}
+	for _, query := range plugin.GraphQLQueries {
+		if query.Scope == "" || query.Scope == graphQLQueryScopeRepo {
+			calls = append(calls, plannedCall{endpoint: "POST /graphql (" + query.Measurement + ")"})
+		}
+	}
+	return calls
+}
+
+func (plugin *GitHub) planOrgCalls(org string) []plannedCall {
+	calls := make([]plannedCall, 0)
+	if plugin.ActionsUsage || plugin.ActionsLeaderboard > 0 || plugin.LFSUsage {
+		calls = append(calls, plannedCall{endpoint: "GET /organizations/" + org + "/settings/billing/usage"})
+	}
+	if plugin.CopilotUsage {
+		calls = append(calls, plannedCall{endpoint: "GET /orgs/" + org + "/copilot/metrics"})
+	}
+	if plugin.AuditLog {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /orgs/" + org + "/audit-log"},
+			plannedCall{endpoint: "GET /orgs/" + org + "/audit-log", per: "additional page"})
+	}
+	if plugin.IPAllowList {
+		calls = append(calls, plannedCall{endpoint: "POST /graphql (ipAllowListEntries)"})
+	}
+	if plugin.SSOCredentials {
+		calls = append(calls, plannedCall{endpoint: "GET /orgs/" + org + "/credential-authorizations"})
+	}
+	if plugin.PATRequests {
+		calls = append(calls, plannedCall{endpoint: "GET /orgs/" + org + "/personal-access-token-requests"})
+	}
+	if plugin.OrgWebhooks {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /orgs/" + org + "/hooks"},
+			plannedCall{endpoint: "GET /orgs/" + org + "/hooks/<id>/deliveries", per: "webhook"})
+	}
+	if plugin.LanguageTrends {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /orgs/" + org + "/repos", per: "discovery interval"},
+			plannedCall{endpoint: "GET /repos/<repo>/languages", per: "org repo and discovery interval"})
+	}
+	if plugin.ClassicProjects {
+		calls = append(calls, plannedCall{endpoint: "GET /orgs/" + org + "/projects"})
+	}
+	if plugin.Rulesets {
+		calls = append(calls,
+			plannedCall{endpoint: "GET /orgs/" + org + "/rulesets"},
+			plannedCall{endpoint: "GET /orgs/" + org + "/rulesets/<id>", per: "ruleset"})
+	}
+	for _, query := range plugin.GraphQLQueries {
+		if query.Scope == graphQLQueryScopeOrg {
+			calls = append(calls, plannedCall{endpoint: "POST /graphql (" + query.Measurement + ")"})
+		}
+	}
+	return calls
+}
+
+func (plugin *GitHub) planGlobalCalls() []plannedCall {
+	calls := make([]plannedCall, 0)
+	for _, repo := range plugin.Repos {
+		if isRepoPattern(repo) {
+			calls = append(calls, plannedCall{endpoint: "GET /orgs/" + strings.Split(repo, "/")[0] + "/repos", per: "discovery interval"})
+		}
+	}
+	for _, topic := range plugin.Topics {
+		calls = append(calls, plannedCall{endpoint: "GET /search/repositories?q=topic:" + topic, per: "discovery interval"})
+	}
+	for _, user := range plugin.Users {
+		calls = append(calls, plannedCall{endpoint: "GET /users/" + user + "/repos", per: "discovery interval"})
+	}
+	for _, entries := range plugin.CostCenters {
+		for _, entry := range entries {
+			if len(entry) > 0 && entry[0] == '@' {
+				calls = append(calls, plannedCall{endpoint: fmt.Sprintf("GET /orgs/<org>/teams/<team>/repos (%s)", entry[1:])})
+			}
+		}
+	}
+	if plugin.AppPermissions {
+		calls = append(calls, plannedCall{endpoint: fmt.Sprintf("GET /app/installations/%d", plugin.InstallationID)})
+	}
+	if plugin.graphQLBatchEnabled() {
+		calls = append(calls, plannedCall{endpoint: "POST /graphql (repository batch)", per: fmt.Sprintf("%d repos", plugin.GraphQLBatchSize)})
+	}
+	return calls
+}
+
+// logDryRun logs the API calls each gather would issue together with the estimated rate limit cost.
+func (plugin *GitHub) logDryRun() {
+	fixed := 0
+	dependent := 0
+	logCalls := func(scope string, calls []plannedCall) {
+		for _, call := range calls {
+			if call.per == "" {
+				fixed++
+				plugin.Log.Infof("Dry run: %s would call %s", scope, call.endpoint)
+			} else {
+				dependent++
+				plugin.Log.Infof("Dry run: %s would call %s (per %s)", scope, call.endpoint, call.per)
+			}
+		}
+	}
+	logCalls("gather", plugin.planGlobalCalls())
+	for _, repo := range plugin.Repos {
+		logCalls("repo '"+repo+"'", plugin.planRepoCalls(repo))
+	}
+	for _, org := range plugin.Orgs {
+		logCalls("org '"+org+"'", plugin.planOrgCalls(org))
+	}
+	plugin.Log.Infof("Dry run: estimated rate limit cost per interval is %d requests plus %d item dependent calls", fixed, dependent)
+}
@@ -0,0 +1,44 @@
+// environments.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processEnvironments(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	environments, _, err := client.Repositories.ListEnvironments(ctx, repoOwner, repoName, &githubApi.EnvironmentListOptions{ListOptions: githubApi.ListOptions{PerPage: 100}})
+	if err != nil {
+		return err
+	}
+	for _, environment := range environments.Environments {
+		requiredReviewers := 0
+		waitTimer := 0
+		for _, protectionRule := range environment.ProtectionRules {
+			switch protectionRule.GetType() {
+			case "required_reviewers":
+				requiredReviewers += len(protectionRule.Reviewers)
+			case "wait_timer":
+				waitTimer = protectionRule.GetWaitTimer()
+			}
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_environment"] = environment.GetName()
+		fields := make(map[string]interface{})
+		fields["protection_rules"] = len(environment.ProtectionRules)
+		fields["required_reviewers"] = requiredReviewers
+		fields["wait_timer"] = waitTimer
+		fields["branch_policy"] = environment.DeploymentBranchPolicy != nil
+		a.AddCounter("github_environment", fields, tags)
+	}
+	return nil
+}
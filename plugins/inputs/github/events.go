@@ -0,0 +1,41 @@
+// events.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processRepoEvents(a telegraf.Accumulator, repo string, state *repoState, repoInfo *githubApi.Repository) {
+	visibility := repoVisibility(repoInfo)
+	if state.Visibility != visibility {
+		plugin.addRepoEvent(a, repo, "visibility_changed", state.Visibility, visibility)
+	}
+	owner := repoInfo.GetOwner().GetLogin()
+	if state.Owner != owner {
+		plugin.addRepoEvent(a, repo, "owner_changed", state.Owner, owner)
+	}
+	defaultBranch := repoInfo.GetDefaultBranch()
+	if state.DefaultBranch != defaultBranch {
+		plugin.addRepoEvent(a, repo, "default_branch_changed", state.DefaultBranch, defaultBranch)
+	}
+}
+
+func (plugin *GitHub) addRepoEvent(a telegraf.Accumulator, repo string, event string, previous string, current string) {
+	if plugin.Debug {
+		plugin.Log.Infof("Repo %s event %s: '%s' -> '%s'", repo, event, previous, current)
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	tags["event"] = event
+	fields := make(map[string]interface{})
+	fields["previous"] = previous
+	fields["current"] = current
+	a.AddFields("github_repo_event", fields, tags)
+}
@@ -0,0 +1,93 @@
+// flavor.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+)
+
+const (
+	flavorGitHub  = "github"
+	flavorGitea   = "gitea"
+	flavorForgejo = "forgejo"
+)
+
+// isGitea reports whether the API is provided by a Gitea (or Forgejo) instance. Such instances offer GitHub compatible
+// repository and release endpoints only; all other collectors are skipped.
+func (plugin *GitHub) isGitea() bool {
+	return plugin.Flavor == flavorGitea || plugin.Flavor == flavorForgejo
+}
+
+func (plugin *GitHub) checkFlavor() error {
+	switch plugin.Flavor {
+	case flavorGitHub:
+		return nil
+	case flavorGitea, flavorForgejo:
+		if plugin.APIBaseURL == "" {
+			return fmt.Errorf("github: Flavor '%s' requires an API base URL", plugin.Flavor)
+		}
+		return nil
+	}
+	return fmt.Errorf("github: Invalid flavor '%s'", plugin.Flavor)
+}
+
+func (plugin *GitHub) newGiteaClient(httpClient *http.Client) (*githubApi.Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(plugin.APIBaseURL, "/") + "/api/v1/")
+	if err != nil {
+		return nil, err
+	}
+	client := githubApi.NewClient(httpClient)
+	client.BaseURL = baseURL
+	client.UploadURL = baseURL
+	return client, nil
+}
+
+// giteaRepository contains the Gitea repository attributes named differently than their GitHub counterparts.
+type giteaRepository struct {
+	FullName      string `json:"full_name"`
+	Owner         *githubApi.User
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+	Size          int    `json:"size"`
+	StarsCount    int    `json:"stars_count"`
+	ForksCount    int    `json:"forks_count"`
+	WatchersCount int    `json:"watchers_count"`
+}
+
+// getRepository fetches the repository info (mapping Gitea's attributes to the GitHub ones if needed).
+func (plugin *GitHub) getRepository(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string) (*githubApi.Repository, error) {
+	if !plugin.isGitea() {
+		repoInfo, _, err := client.Repositories.Get(ctx, repoOwner, repoName)
+		return repoInfo, err
+	}
+	request, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s", repoOwner, repoName), nil)
+	if err != nil {
+		return nil, err
+	}
+	giteaRepo := &giteaRepository{}
+	_, err = client.Do(ctx, request, giteaRepo)
+	if err != nil {
+		return nil, err
+	}
+	return &githubApi.Repository{
+		FullName:         githubApi.String(giteaRepo.FullName),
+		Owner:            giteaRepo.Owner,
+		Private:          githubApi.Bool(giteaRepo.Private),
+		DefaultBranch:    githubApi.String(giteaRepo.DefaultBranch),
+		Size:             githubApi.Int(giteaRepo.Size),
+		StargazersCount:  githubApi.Int(giteaRepo.StarsCount),
+		ForksCount:       githubApi.Int(giteaRepo.ForksCount),
+		SubscribersCount: githubApi.Int(giteaRepo.WatchersCount),
+	}, nil
+}
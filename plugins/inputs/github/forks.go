@@ -0,0 +1,74 @@
+// forks.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// externalAuthorAssociations are the author associations of pull request authors without write access to the repo.
+var externalAuthorAssociations = map[string]bool{
+	"CONTRIBUTOR":            true,
+	"FIRST_TIME_CONTRIBUTOR": true,
+	"FIRST_TIMER":            true,
+	"NONE":                   true,
+}
+
+// processForkConversion compares the forks created within the window with the pull requests opened by external authors
+// (i.e. authors without write access) within the window, indicating whether forks turn into contributions.
+func (plugin *GitHub) processForkConversion(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, recentIssues []*githubApi.Issue) error {
+	since := time.Now().Add(-plugin.window)
+	newForks, err := plugin.countRecentForks(ctx, client, repoOwner, repoName, since)
+	if err != nil {
+		return err
+	}
+	externalPullRequests := 0
+	for _, issue := range recentIssues {
+		if issue.IsPullRequest() && externalAuthorAssociations[issue.GetAuthorAssociation()] {
+			externalPullRequests++
+		}
+	}
+	var conversionRatio float64
+	if newForks > 0 {
+		conversionRatio = float64(externalPullRequests) / float64(newForks)
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["new_forks"] = newForks
+	fields["external_pull_requests"] = externalPullRequests
+	fields["fork_conversion_ratio"] = conversionRatio
+	a.AddCounter("github_fork_conversion", fields, tags)
+	return nil
+}
+
+func (plugin *GitHub) countRecentForks(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, since time.Time) (int, error) {
+	recentForks := 0
+	opts := &githubApi.RepositoryListForksOptions{Sort: "newest", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		forks, response, err := client.Repositories.ListForks(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, fork := range forks {
+			if fork.GetCreatedAt().Before(since) {
+				return recentForks, nil
+			}
+			recentForks++
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return recentForks, nil
+}
@@ -12,46 +12,551 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	githubApi "github.com/google/go-github/v44/github"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
-	"golang.org/x/oauth2"
 )
 
 type GitHub struct {
-	Repos       []string `toml:"repos"`
-	APIBaseURL  string   `toml:"api_base_url"`
-	AccessToken string   `toml:"access_token"`
+	Repos             []string        `toml:"repos"`
+	ReposFile         string          `toml:"repos_file"`
+	ReposExclude      []string        `toml:"repos_exclude"`
+	Topics            []string        `toml:"topics"`
+	TopicsOrg         string          `toml:"topics_org"`
+	Users             []string        `toml:"users"`
+	IncludeForks      bool            `toml:"include_forks"`
+	IncludeArchived   bool            `toml:"include_archived"`
+	DiscoveryInterval string          `toml:"discovery_interval"`
+	RepoChurn         bool            `toml:"repo_churn"`
+	Orgs              []string        `toml:"orgs"`
+	APIBaseURL        string          `toml:"api_base_url"`
+	Flavor            string          `toml:"flavor"`
+	Anonymous         bool            `toml:"anonymous"`
+	AccessToken       config.Secret   `toml:"access_token"`
+	BackupAccessToken config.Secret   `toml:"backup_access_token"`
+	AccessTokens      []config.Secret `toml:"access_tokens"`
+	AccessTokenFile   string          `toml:"access_token_file"`
+	ValidateAccess    bool            `toml:"validate_access"`
+	TokenRotation     string          `toml:"token_rotation"`
+	Window            string          `toml:"window"`
 
-	Timeout int  `toml:"timeout"`
-	Debug   bool `toml:"debug"`
+	Referrers     bool `toml:"referrers"`
+	PopularPaths  bool `toml:"popular_paths"`
+	TrafficSeries bool `toml:"traffic_series"`
+	TrafficExport bool `toml:"traffic_export"`
+
+	TrafficBreakdown string `toml:"traffic_breakdown"`
+
+	WorkflowSchedules   bool `toml:"workflow_schedules"`
+	WorkflowConcurrency bool `toml:"workflow_concurrency"`
+	WorkflowReruns      bool `toml:"workflow_reruns"`
+	Environments        bool `toml:"environments"`
+	Deployments         bool `toml:"deployments"`
+	TagProtection       bool `toml:"tag_protection"`
+	IssueTriage         bool `toml:"issue_triage"`
+	IssueForms          bool `toml:"issue_forms"`
+	ClassicProjects     bool `toml:"classic_projects"`
+	Rulesets            bool `toml:"rulesets"`
+	Submodules          bool `toml:"submodules"`
+	Activity            bool `toml:"activity"`
+
+	DependencyPullRequests bool     `toml:"dependency_pull_requests"`
+	DependencyBots         []string `toml:"dependency_bots"`
+	PullRequestBranches    bool     `toml:"pull_request_branches"`
+	MaxPullRequestBranches int      `toml:"max_pull_request_branches"`
+	MergeConflicts         bool     `toml:"merge_conflicts"`
+	Codeowners             bool     `toml:"codeowners"`
+	StatusContexts         bool     `toml:"status_contexts"`
+	ForkConversion         bool     `toml:"fork_conversion"`
+	StargazerLocations     bool     `toml:"stargazer_locations"`
+	StargazerSamplePages   int      `toml:"stargazer_sample_pages"`
+	StatusContextCommits   int      `toml:"status_context_commits"`
+	MaxStatusContexts      int      `toml:"max_status_contexts"`
+
+	ActivityPaths     []string `toml:"activity_paths"`
+	LatestRelease     bool     `toml:"latest_release"`
+	ReleaseCadence    bool     `toml:"release_cadence"`
+	ReleaseNotes      bool     `toml:"release_notes"`
+	TagCoverage       bool     `toml:"tag_coverage"`
+	ReleaseStats      bool     `toml:"release_stats"`
+	ReleaseAssets     bool     `toml:"release_assets"`
+	ReleaseAssetTypes bool     `toml:"release_asset_types"`
+	ReleaseDigests    bool     `toml:"release_digests"`
+	RepoEvents        bool     `toml:"repo_events"`
+
+	MaxReleasePages    int  `toml:"max_release_pages"`
+	IncludePrereleases bool `toml:"include_prereleases"`
+	IncludeDrafts      bool `toml:"include_drafts"`
+
+	AssetInclude     []string `toml:"asset_include"`
+	AssetExclude     []string `toml:"asset_exclude"`
+	ExcludeBotAssets bool     `toml:"exclude_bot_assets"`
+	BotAssets        []string `toml:"bot_assets"`
+
+	MaintenanceBranches []string `toml:"maintenance_branches"`
+
+	ReleaseMirrors []string `toml:"release_mirrors"`
+
+	ReleaseSignatures  bool     `toml:"release_signatures"`
+	SignaturePatterns  []string `toml:"signature_patterns"`
+	SBOMPatterns       []string `toml:"sbom_patterns"`
+	ProvenancePatterns []string `toml:"provenance_patterns"`
+
+	ActionsUsage       bool   `toml:"actions_usage"`
+	ActionsLeaderboard int    `toml:"actions_leaderboard"`
+	CopilotUsage       bool   `toml:"copilot_usage"`
+	AuditLog           bool   `toml:"audit_log"`
+	AuditLogWindow     string `toml:"audit_log_window"`
+	IPAllowList        bool   `toml:"ip_allow_list"`
+	SSOCredentials     bool   `toml:"sso_credentials"`
+	PATRequests        bool   `toml:"pat_requests"`
+	OrgWebhooks        bool   `toml:"org_webhooks"`
+	LanguageTrends     bool   `toml:"language_trends"`
+
+	LFSUsage          bool    `toml:"lfs_usage"`
+	LFSStorageQuota   float64 `toml:"lfs_storage_quota"`
+	LFSBandwidthQuota float64 `toml:"lfs_bandwidth_quota"`
+
+	StatusPage       bool     `toml:"status_page"`
+	StatusPageURL    string   `toml:"status_page_url"`
+	StatusComponents []string `toml:"status_components"`
+
+	CostCenters map[string][]string `toml:"cost_centers"`
+
+	HomebrewAPIURL   string            `toml:"homebrew_api_url"`
+	HomebrewFormulae map[string]string `toml:"homebrew_formulae"`
+
+	AppID                  int64             `toml:"app_id"`
+	InstallationID         int64             `toml:"installation_id"`
+	PrivateKey             string            `toml:"private_key"`
+	PrivateKeyPath         string            `toml:"private_key_path"`
+	AppPermissions         bool              `toml:"app_permissions"`
+	ExpectedAppPermissions map[string]string `toml:"expected_app_permissions"`
+
+	StargazerLocationsInterval string              `toml:"stargazer_locations_interval"`
+	StargazerLocationKeywords  map[string][]string `toml:"stargazer_location_keywords"`
+
+	GraphQLQueries []*GraphQLQuery `toml:"graphql_queries"`
+
+	Collectors       []string `toml:"collectors"`
+	ReleasesInterval string   `toml:"releases_interval"`
+	TrafficInterval  string   `toml:"traffic_interval"`
+
+	MetricType    string `toml:"metric_type"`
+	MetricVersion int    `toml:"metric_version"`
+
+	Timeout            int    `toml:"timeout"`
+	GatherInterval     string `toml:"gather_interval"`
+	MaxConcurrentRepos int    `toml:"max_concurrent_repos"`
+	GraphQLBatch       bool   `toml:"graphql_batch"`
+	GraphQLBatchSize   int    `toml:"graphql_batch_size"`
+	CacheDir           string `toml:"cache_dir"`
+	StateFile          string `toml:"state_file"`
+	DryRun             bool   `toml:"dry_run"`
+	Backoff            bool   `toml:"backoff"`
+	Retries            int    `toml:"retries"`
+	CircuitFailures    int    `toml:"circuit_failures"`
+	CircuitGathers     int    `toml:"circuit_gathers"`
+	RetryMaxWait       int    `toml:"retry_max_wait"`
+	RateLimits         bool   `toml:"rate_limits"`
+	RateLimitsSource   string `toml:"rate_limits_source"`
+	RateLimitThreshold int    `toml:"rate_limit_threshold"`
+	LogSummary         bool   `toml:"log_summary"`
+	Debug              bool   `toml:"debug"`
 
 	Log telegraf.Logger
+
+	window             time.Duration
+	auditLogWindow     time.Duration
+	discoveryInterval  time.Duration
+	gatherInterval     time.Duration
+	discoveredRepos    map[string]*discoveredRepos
+	repoChurns         map[string]*repoChurn
+	orgLanguages       map[string]map[string]int
+	orgLanguagesAt     map[string]time.Time
+	collectorIntervals map[string]time.Duration
+	collectorRuns      map[string]time.Time
+	prefetchedRepos    map[string]*prefetchedRepo
+	client             *githubApi.Client
+	externalClient     *http.Client
+	rateLimitUsage     *rateLimitUsage
+	gatherSummary      *gatherSummary
+	tokens             []*config.Secret
+	tokenState         tokenState
+	tokenFile          tokenFile
+	tokenRotation      tokenRotation
+	installationToken  installationToken
+	appJWT             appJWT
+	appClient          *githubApi.Client
+	backoffState       backoffState
+	circuitBreaker     circuitBreaker
+	anonymousOffset    int
+	stateMutex         sync.Mutex
+	releaseDigests     map[string]string
+	excludedReleases   map[string]bool
+	issueTriages       map[string]map[int]*closedIssueTriage
+	pullRequestPaths   map[string]map[int]*closedPullRequestPaths
+	codeownerChecks    map[string]*codeownerCheck
+	repoStates         map[string]*repoState
 }
 
 func NewGitHub() *GitHub {
 	return &GitHub{
-		Repos:       []string{},
-		AccessToken: "",
-		Timeout:     10,
+		Repos:             []string{},
+		ReposExclude:      []string{},
+		Topics:            []string{},
+		Users:             []string{},
+		Orgs:              []string{},
+		Flavor:            flavorGitHub,
+		AccessTokens:      []config.Secret{},
+		TokenRotation:     tokenRotationRateLimit,
+		ValidateAccess:    true,
+		Window:            defaultWindow,
+		AuditLogWindow:    defaultAuditLogWindow,
+		DiscoveryInterval: defaultDiscoveryInterval,
+		Collectors:        []string{collectorInfo, collectorReleases, collectorTraffic},
+		MetricType:        metricTypeCounter,
+		Timeout:           10,
+
+		MaxConcurrentRepos: 1,
+		GraphQLBatchSize:   10,
+		RateLimitsSource:   rateLimitsSourceHeaders,
+		RetryMaxWait:       60,
+		CircuitGathers:     10,
+
+		TrafficBreakdown: "day",
+
+		MaxReleasePages:    10,
+		IncludePrereleases: true,
+		IncludeDrafts:      true,
+		AssetInclude:       []string{},
+		AssetExclude:       []string{},
+		BotAssets:          defaultBotAssets,
+
+		LFSStorageQuota:   10,
+		LFSBandwidthQuota: 10,
+
+		StatusPageURL:    defaultStatusPageURL,
+		HomebrewAPIURL:   defaultHomebrewAPIURL,
+		StatusComponents: []string{"API Requests", "Actions", "Packages", "Pages"},
+
+		DependencyBots:         []string{"dependabot[bot]", "renovate[bot]"},
+		MaxPullRequestBranches: 10,
+		StatusContextCommits:   5,
+		StargazerSamplePages:   1,
+
+		StargazerLocationsInterval: "1w",
+		MaxStatusContexts:          20,
+		MaintenanceBranches:        []string{},
+		ReleaseMirrors:             []string{},
+		ActivityPaths:              []string{},
+		GraphQLQueries:             []*GraphQLQuery{},
+
+		SignaturePatterns:  []string{"*.sig", "*.asc", "*.sigstore", "*.sigstore.json"},
+		SBOMPatterns:       []string{"*.spdx", "*.spdx.json", "*.cdx.json", "*.cdx.xml", "*sbom*"},
+		ProvenancePatterns: []string{"*.intoto.jsonl", "*provenance*"},
+
+		discoveredRepos:  make(map[string]*discoveredRepos),
+		repoChurns:       make(map[string]*repoChurn),
+		orgLanguages:     make(map[string]map[string]int),
+		orgLanguagesAt:   make(map[string]time.Time),
+		collectorRuns:    make(map[string]time.Time),
+		releaseDigests:   make(map[string]string),
+		excludedReleases: make(map[string]bool),
+		issueTriages:     make(map[string]map[int]*closedIssueTriage),
+		pullRequestPaths: make(map[string]map[int]*closedPullRequestPaths),
+		codeownerChecks:  make(map[string]*codeownerCheck),
+		repoStates:       make(map[string]*repoState),
 	}
 }
 
 func (plugin *GitHub) SampleConfig() string {
 	return `
-  ## The repositories (<owner>/<repo>) to query
+  ## The repositories (<owner>/<repo>) to query; use glob patterns (e.g. "<org>/*" or "<org>/terraform-*") to query all
+  ## matching repos of an org
   repos = ["influxdata/telegraf"]
+  ## The file to read additional repos (or repo patterns) from (one per line, # starts a comment); re-read every gather
+  # repos_file = ""
+  ## The glob patterns of the repos to exclude from the repos above and the users' repos below (regular expressions are
+  ## not supported)
+  # repos_exclude = []
+  ## The topics to query all repos for (as found by the search API) and the org to optionally restrict the search to
+  # topics = []
+  # topics_org = ""
+  ## The users to query all (public) repos for
+  # users = []
+  ## Whether to include forked respectively archived repos when querying all repos of an org, topic or user
+  # include_forks = false
+  # include_archived = false
+  ## How often to refresh the repos of the orgs, topics and users above (as duration or days/weeks)
+  # discovery_interval = "1d"
+  ## Report the number of repos created and removed (deleted or archived) per org pattern whenever its repos are
+  ## rediscovered
+  # repo_churn = false
+  ## The organizations to query for organization level stats (requires org admin access)
+  # orgs = []
   ## The API base URL to use for API access (empty URL defaults to https://api.github.com/)
   # api_base_url = ""
-  ## The Personal Access Token to use for API access
+  ## The API flavor (github, or gitea/forgejo for GitHub compatible instances; the latter only support the repo info
+  ## and release based metrics and require the instance URL as api_base_url)
+  # flavor = "github"
+  ## Only gather the repo info and release based metrics of public repos without authentication; repos are gathered
+  ## one after another and skipped as soon as the unauthenticated rate limit (60 requests per hour) is used up (if the
+  ## gather interval is set, every gather only uses its share of the rate limit); skipped repos are gathered first
+  ## during the next gather
+  # anonymous = false
+  ## The Personal Access Token to use for API access (all access tokens may also reference a secret store, e.g.
+  ## "@{vault:github_token}")
   # access_token = ""
+  ## The backup Personal Access Token to fail over to in case the active one is rejected (e.g. during token rotation);
+  ## requires access_token
+  # backup_access_token = ""
+  ## Multiple Personal Access Tokens to spread the requests across (instead of the access tokens above), rotating
+  ## either on every request (round-robin) or whenever the active token's rate limit is used up (rate-limit)
+  # access_tokens = []
+  # token_rotation = "rate-limit"
+  ## The file to read the access token from (instead of the access tokens above); the file is re-read whenever it
+  ## changes, so tokens rotated by an external process (e.g. Vault agent) are picked up without restart
+  # access_token_file = ""
+  ## Verify the access token and its access to the repos above (one request per repo) during startup; a rejected token
+  ## fails the startup, missing scopes and repos not visible to the token are logged
+  # validate_access = true
+  ## The base repo stats to gather: the repo info (stars, forks, ...), the releases (download counts and all release based
+  ## metrics) and the traffic (views and clones; requires the access token above)
+  # collectors = ["info", "releases", "traffic"]
+  ## How often to run the releases and traffic collectors per repo (as duration or days/weeks; empty to run them every gather)
+  # releases_interval = ""
+  # traffic_interval = ""
+  ## The lookback window of all windowed metrics (e.g. deployments, issue triage, activity) as duration or days/weeks
+  # window = "7d"
+  ## The granularity (day or week) of the traffic views and clones (latest entry is reported)
+  # traffic_breakdown = "day"
+  ## Emit every traffic view and clone entry returned by the API as a point timestamped at the entry's day or week (requires the access token above)
+  # traffic_series = false
+  ## Emit all daily traffic view and clone entries on every gather with the fields of GitHub's Insights CSV export (date,
+  ## count and uniques per day), to ease reconciliation with the numbers shown by GitHub (requires the day breakdown)
+  # traffic_export = false
+  ## Gather the top referrers of the last 14 days (requires the access token above)
+  # referrers = false
+  ## Gather the most popular content paths of the last 14 days (requires the access token above)
+  # popular_paths = false
+  ## Gather schedule drift of cron scheduled workflows (requires additional API calls per workflow)
+  # workflow_schedules = false
+  ## Gather the number of workflow runs within the window cancelled due to their concurrency group (superseded by a newer run)
+  # workflow_concurrency = false
+  ## Gather the number and ratio of workflow runs within the window that have been re-run (a proxy for flaky workflows)
+  # workflow_reruns = false
+  ## Gather deployment environment protection rules
+  # environments = false
+  ## Gather deployment duration and failure rate per environment within the window
+  # deployments = false
+  ## Gather the tag rulesets (including the ones inherited from the org) and their protected tag patterns
+  # tag_protection = false
+  ## Gather the average time until issues opened within the window got labeled or assigned
+  # issue_triage = false
+  ## Gather the fraction of issues opened within the window via issue forms (or templates) vs. blank issues
+  # issue_forms = false
+  ## Gather the number of remaining (legacy) classic projects of the repos and orgs
+  # classic_projects = false
+  ## Gather the number of bypass actors per ruleset of the repos and orgs (requires admin access)
+  # rulesets = false
+  ## Gather how many commits the pinned submodule commits lag behind the upstream default branch
+  # submodules = false
+  ## Gather the number of issues, pull requests and comments within the window split by human and bot authors
+  # activity = false
+  ## Gather the number and max age of open pull requests authored by the dependency bots below
+  # dependency_pull_requests = false
+  # dependency_bots = ["dependabot[bot]", "renovate[bot]"]
+  ## Gather the number of open pull requests per base branch (branches beyond the limit are summed up as "<other>")
+  # pull_request_branches = false
+  # max_pull_request_branches = 10
+  ## Gather the number of open pull requests conflicting with their base branch (requires the access token above)
+  # merge_conflicts = false
+  ## Gather the CODEOWNERS owners no longer being org members (respectively teams) and the open pull requests awaiting
+  ## their review (org repos only; the owners are re-checked once per discovery_interval)
+  # codeowners = false
+  ## Gather the status contexts (commit statuses and check runs) reported for the latest commits of the default branch,
+  ## flagging contexts missing on the latest commit (reporting at most max_status_contexts contexts individually)
+  # status_contexts = false
+  # status_context_commits = 5
+  # max_status_contexts = 20
+  ## Gather the forks created and the pull requests opened by external authors within the window and their ratio
+  # fork_conversion = false
+  ## Sample the most recent stargazers (100 per page) and count them per location bucket (matching the profile location
+  ## against the keywords below); as the estimate changes slowly, the sample is only taken once per interval
+  # stargazer_locations = false
+  # stargazer_sample_pages = 1
+  # stargazer_locations_interval = "1w"
+  ## The path prefixes (e.g. monorepo components) to gather commit and pull request counts within the window for
+  # activity_paths = []
+  ## The maximum number of release pages (100 releases each) to evaluate (0 for no limit)
+  # max_release_pages = 10
+  ## Whether to count pre-releases and draft releases towards the total download count
+  # include_prereleases = true
+  # include_drafts = true
+  ## The asset file name glob patterns to count downloads for (empty list for all assets) respectively to ignore (regular
+  ## expressions are not supported)
+  # asset_include = []
+  # asset_exclude = ["*.sig", "*.asc", "*.sha256", "checksums.txt", "*.spdx.json"]
+  ## Exclude the assets mostly fetched by automation (checksums, signatures, SBOMs) from the total download count and
+  ## report their downloads separately (bot_download_count)
+  # exclude_bot_assets = false
+  # bot_assets = ["*.sha256", "*.sha512", "*.md5", "*checksums.txt", "*.sig", "*.asc", "*.pem", "*.sbom.json", "*.spdx.json", "*.intoto.jsonl"]
+  ## Gather the download count, age and publishing time (unix seconds) of the latest (non-draft) release (pre-releases
+  ## are considered according to include_prereleases)
+  # latest_release = false
+  ## Gather the days since the latest release and the number of releases within the last 90 days (ignoring drafts,
+  ## pre-releases are considered according to include_prereleases)
+  # release_cadence = false
+  ## Gather the number of releases published within the window without release notes (empty body)
+  # release_notes = false
+  ## Gather the number of git tags lacking a corresponding release (within the evaluated release pages)
+  # tag_coverage = false
+  ## Gather the download count, asset count and publishing time (unix seconds) per release
+  # release_stats = false
+  ## Gather the download count and size (in bytes) per release asset
+  # release_assets = false
+  ## Gather the download count and number of assets per asset type (archive, installer, package, checksum, signature
+  ## and other; derived from the asset's file name and content type)
+  # release_asset_types = false
+  ## Gather release asset digests to detect replaced assets of existing releases
+  # release_digests = false
+  ## Emit event points on repository visibility, owner or default branch changes between gathers
+  # repo_events = false
+  ## The maintenance branches to gather the latest release (the latest of the 10 most recent releases the branch contains
+  ## according to the compare API) and its age for
+  # maintenance_branches = []
+  ## The mirror URL templates (supporting the {owner}, {repo}, {tag} and {asset} placeholders) to probe for the latest
+  ## release's assets (e.g. "https://downloads.example.com/{repo}/{tag}/{asset}")
+  # release_mirrors = []
+  ## Gather counts of releases providing signature, SBOM and provenance assets (identified by the file name patterns below)
+  # release_signatures = false
+  # signature_patterns = ["*.sig", "*.asc", "*.sigstore", "*.sigstore.json"]
+  # sbom_patterns = ["*.spdx", "*.spdx.json", "*.cdx.json", "*.cdx.xml", "*sbom*"]
+  # provenance_patterns = ["*.intoto.jsonl", "*provenance*"]
+  ## Gather the Actions minutes of the current month per repo and runner type for the orgs above (requires billing access)
+  # actions_usage = false
+  ## Gather the top N repos by Actions minutes of the current month for the orgs above (0 to disable; requires billing access)
+  # actions_leaderboard = 0
+  ## Gather the daily Copilot code completion metrics (org total as well as per language and editor) for the orgs above
+  # copilot_usage = false
+  ## Gather audit log event counts within the audit log window per action for the orgs above (requires GitHub Enterprise
+  ## Cloud); the audit log window is kept separate from the window above, as the audit log is usually busy
+  # audit_log = false
+  # audit_log_window = "1d"
+  ## Gather the IP allow list state and entry count for the orgs above (requires an access token)
+  # ip_allow_list = false
+  ## Gather the number of SSO authorized credentials (PATs, SSH keys) for the orgs above (requires SAML SSO)
+  # sso_credentials = false
+  ## Gather the number of pending fine-grained personal access token requests for the orgs above
+  # pat_requests = false
+  ## Gather the org webhooks' active state and last delivery status for the orgs above (requires admin:org_hook scope)
+  # org_webhooks = false
+  ## Gather the language bytes summed up across the repos of the orgs above and their change since the previous gather
+  ## (issues one request per repo, hence it is only gathered once per discovery interval)
+  # language_trends = false
+  ## Gather the Git LFS storage and bandwidth usage of the current month vs. the quotas below (in GB) for the orgs above
+  # lfs_usage = false
+  # lfs_storage_quota = 10.0
+  # lfs_bandwidth_quota = 10.0
+  ## Gather the state of the GitHub platform components below from the status page (empty list for all components)
+  # status_page = false
+  # status_page_url = "https://www.githubstatus.com/api/v2/components.json"
+  # status_components = ["API Requests", "Actions", "Packages", "Pages"]
+  ## The Homebrew API to gather the install analytics of the formulae mapped below from
+  # homebrew_api_url = "https://formulae.brew.sh/api"
+  ## The GitHub App (id and private key) and installation to check the granted permissions for; if no access token is
+  ## configured, the plugin also authenticates as this app installation (refreshing the installation token as needed)
+  # app_id = 0
+  # installation_id = 0
+  ## The app's private key, either inline (PEM encoded) or as file
+  # private_key = ""
+  # private_key_path = ""
+  ## Gather the app installation's granted permissions and flag drift from the expected permissions below
+  # app_permissions = false
+  ## The metric type (counter, gauge or untyped) to use for the gathered stats
+  # metric_type = "counter"
+  ## Pin the metric layout version to keep it across breaking metric changes (0 = the original layout); a pinned
+  ## version is reported via a schema_version tag on all points
+  # metric_version = 0
   ## The http timeout to use (in seconds)
   # timeout = 10
+  ## The interval each gather is expected to finish in (usually the Telegraf interval; empty to disable); repos not
+  ## started before the interval has passed are skipped and the overrun is reported via the github_gather measurement
+  # gather_interval = ""
+  ## The maximum number of repos to gather concurrently
+  # max_concurrent_repos = 1
+  ## Fetch the repo info and releases of up to graphql_batch_size repos with a single GraphQL query (instead of
+  ## individual REST requests per repo; requires authentication)
+  # graphql_batch = false
+  # graphql_batch_size = 10
+  ## The directory to persist responses in, to revalidate them via conditional requests not counting against the rate limit
+  # cache_dir = ""
+  ## The file to persist the state tracked across gathers in (e.g. the last emitted traffic series entries and release
+  ## digests); required to keep the state across restarts, as the execd shim does not persist it
+  # state_file = ""
+  ## Skip an exponentially growing number of gathers while GitHub responds with server errors or rate limit errors
+  # backoff = false
+  ## The number of retries for requests failing with server errors or secondary rate limit errors (honoring the
+  ## Retry-After header) and the maximum time to wait for a single retry (in seconds)
+  # retries = 0
+  # retry_max_wait = 60
+  ## Skip repos failing for the given number of consecutive gathers (0 to disable) for the given number of gathers
+  # circuit_failures = 0
+  # circuit_gathers = 10
+  ## Report the remaining rate limit per resource (core, search, graphql) as seen in the response headers (headers) or as
+  ## returned by the rate limit endpoint (endpoint)
+  # rate_limits = false
+  # rate_limits_source = "headers"
+  ## Only gather the basic repo stats (skipping releases, traffic, optional repo stats and org stats) once the remaining
+  ## core rate limit falls below this threshold (0 to disable)
+  # rate_limit_threshold = 0
+  ## Only log the API calls each gather would issue and their estimated rate limit cost (e.g. via telegraf --test)
+  # dry_run = false
+  ## Log a single structured (JSON) summary line per gather (repos processed, API calls issued and failed API calls per
+  ## endpoint)
+  # log_summary = false
   ## Enable debug output
   # debug = false
+  ## Cost center mapping (cost center to repos or teams) used to add a cost_center tag to all repo metrics
+  ## Repos may be given as glob patterns (e.g. "myorg/infra-*"), teams are referenced via "@<org>/<team-slug>"
+  # [inputs.github.cost_centers]
+  #   platform = ["myorg/infra-*", "@myorg/platform-team"]
+  ## Homebrew formula mapping (repo to formula name) used to gather the install analytics of the repo's formula
+  # [inputs.github.homebrew_formulae]
+  #   "myorg/mytool" = "mytool"
+  ## The location buckets (bucket name to location keywords) to count the sampled stargazers in
+  # [inputs.github.stargazer_location_keywords]
+  #   "DE" = ["germany", "deutschland", "berlin", "munich"]
+  #   "US" = ["usa", "united states", "san francisco", "new york"]
+  ## The expected app installation permissions (permission name to access level)
+  # [inputs.github.expected_app_permissions]
+  #   contents = "read"
+  #   metadata = "read"
+  ## Custom GraphQL queries emitting the values at the given result paths (dot separated, numeric segments indexing
+  ## lists) as fields respectively tags of the given measurement; repo scoped queries are run per repo (providing the
+  ## variables $owner, $name and $repo), org scoped queries per org (providing the variable $org)
+  # [[inputs.github.graphql_queries]]
+  #   measurement = "github_discussions"
+  #   scope = "repo"
+  #   query = '''
+  #     query($owner: String!, $name: String!) {
+  #       repository(owner: $owner, name: $name) {
+  #         discussions { totalCount }
+  #         discussionCategories(first: 1) { totalCount }
+  #       }
+  #     }
+  #   '''
+  #   [inputs.github.graphql_queries.fields]
+  #     discussions = "repository.discussions.totalCount"
+  #     discussion_categories = "repository.discussionCategories.totalCount"
  `
 }
 
@@ -59,71 +564,479 @@ func (plugin *GitHub) Description() string {
 	return "Gather GitHub stats"
 }
 
-func (plugin *GitHub) Gather(a telegraf.Accumulator) error {
-	if len(plugin.Repos) == 0 {
-		return errors.New("github: Empty repo list")
+func (plugin *GitHub) Init() error {
+	if plugin.DryRun {
+		plugin.logDryRun()
+		return nil
+	}
+	err := plugin.checkConfig()
+	if err != nil {
+		return err
+	}
+	err = plugin.loadStateFile()
+	if err != nil {
+		return err
 	}
 	ctx := context.Background()
-	client, err := plugin.getClient(ctx)
+	plugin.client, err = plugin.getClient(ctx)
 	if err != nil {
 		return err
 	}
-	for _, repo := range plugin.Repos {
-		a.AddError(plugin.processRepo(ctx, client, a, repo))
+	if plugin.ValidateAccess && plugin.authenticated() && !plugin.isGitea() {
+		return plugin.validateAccess(ctx, plugin.client)
 	}
 	return nil
 }
 
-func (plugin *GitHub) processRepo(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string) error {
-	if plugin.Debug {
-		plugin.Log.Infof("Processing repo: %s", repo)
+// checkConfig validates the configuration and derives the parsed settings.
+func (plugin *GitHub) checkConfig() error {
+	window, err := parseWindow(plugin.Window)
+	if err != nil {
+		return err
 	}
-	repoOwner, repoName, err := plugin.splitRepoId(repo)
+	plugin.window = window
+	auditLogWindow, err := parseWindow(plugin.AuditLogWindow)
+	if err != nil {
+		return fmt.Errorf("github: Invalid audit log window '%s'", plugin.AuditLogWindow)
+	}
+	plugin.auditLogWindow = auditLogWindow
+	discoveryInterval, err := parseWindow(plugin.DiscoveryInterval)
+	if err != nil {
+		return fmt.Errorf("github: Invalid discovery interval '%s'", plugin.DiscoveryInterval)
+	}
+	plugin.discoveryInterval = discoveryInterval
+	plugin.gatherInterval = 0
+	if plugin.GatherInterval != "" {
+		gatherInterval, err := parseWindow(plugin.GatherInterval)
+		if err != nil {
+			return fmt.Errorf("github: Invalid gather interval '%s'", plugin.GatherInterval)
+		}
+		plugin.gatherInterval = gatherInterval
+	}
+	if plugin.TrafficBreakdown != "day" && plugin.TrafficBreakdown != "week" {
+		return fmt.Errorf("github: Invalid traffic breakdown '%s'", plugin.TrafficBreakdown)
+	}
+	if plugin.TrafficExport && plugin.TrafficBreakdown != "day" {
+		return fmt.Errorf("github: Traffic export requires the day traffic breakdown (not '%s')", plugin.TrafficBreakdown)
+	}
+	err = plugin.checkFlavor()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkPatterns()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkCollectors()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkMetricType()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkMetricVersion()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkRateLimitsSource()
 	if err != nil {
 		return err
 	}
-	repoInfo, _, err := client.Repositories.Get(ctx, repoOwner, repoName)
+	err = plugin.checkBackupAccessToken()
 	if err != nil {
 		return err
 	}
-	repoReleases, _, err := client.Repositories.ListReleases(ctx, repoOwner, repoName, nil)
+	err = plugin.checkTokenRotation()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkAccessTokenFile()
+	if err != nil {
+		return err
+	}
+	err = plugin.checkGraphQLQueries()
+	if err != nil {
+		return err
+	}
+	return plugin.checkAnonymous()
+}
+
+func (plugin *GitHub) Gather(a telegraf.Accumulator) error {
+	if len(plugin.Repos) == 0 && plugin.ReposFile == "" && len(plugin.Topics) == 0 && len(plugin.Users) == 0 && len(plugin.Orgs) == 0 && !plugin.AppPermissions && !plugin.StatusPage {
+		return errors.New("github: Empty repo, topic, user and org list")
+	}
+	if plugin.DryRun {
+		return nil
+	}
+	gatherStart := time.Now()
+	err := plugin.checkConfig()
+	if err != nil {
+		return err
+	}
+	if plugin.MetricType != metricTypeCounter {
+		a = &metricTypeAccumulator{Accumulator: a, metricType: plugin.MetricType}
+	}
+	if plugin.MetricVersion > 0 {
+		a = &schemaVersionAccumulator{Accumulator: a, schemaVersion: strconv.Itoa(plugin.MetricVersion)}
+	}
+	if plugin.Backoff && plugin.backoffState.skipGather() {
+		if plugin.Debug {
+			plugin.Log.Infof("Skipping gather due to back-off level %d", plugin.backoffState.currentLevel())
+		}
+		fields := make(map[string]interface{})
+		fields["rate_limit_cost"] = 0
+		fields["backoff_level"] = plugin.backoffState.currentLevel()
+		a.AddCounter("github_gather", fields, make(map[string]string))
+		return nil
+	}
+	ctx := context.Background()
+	plugin.rateLimitUsage = newRateLimitUsage()
+	if plugin.LogSummary {
+		plugin.gatherSummary = newGatherSummary(gatherStart)
+	}
+	// the client is normally created during Init; create it lazily for callers skipping Init
+	if plugin.client == nil {
+		plugin.client, err = plugin.getClient(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	client := plugin.client
+	if len(plugin.CostCenters) > 0 {
+		mapping, err := plugin.resolveCostCenters(ctx, client)
+		if err != nil {
+			return err
+		}
+		a = &costCenterAccumulator{Accumulator: a, mapping: mapping}
+	}
+	repos, err := plugin.resolveRepos(ctx, client)
 	if err != nil {
 		return err
 	}
-	totalDownloadCount := 0
-	for _, repoRelease := range repoReleases {
-		for _, repoReleaseAsset := range repoRelease.Assets {
-			totalDownloadCount += repoReleaseAsset.GetDownloadCount()
+	plugin.processRepoChurns(a)
+	if plugin.graphQLBatchEnabled() {
+		plugin.prefetchRepos(ctx, client, repos)
+	}
+	skippedRepos := plugin.processRepos(ctx, client, a, repos, gatherStart)
+	if !plugin.isGitea() && !plugin.Anonymous {
+		for _, org := range plugin.Orgs {
+			if plugin.rateLimitThrottled() {
+				plugin.Log.Warnf("Skipping org stats of org %s due to remaining rate limit below %d", org, plugin.RateLimitThreshold)
+				continue
+			}
+			a.AddError(plugin.processOrg(ctx, client, a, org))
 		}
 	}
+	if plugin.AppPermissions && !plugin.isGitea() && !plugin.Anonymous {
+		a.AddError(plugin.processAppPermissions(ctx, a))
+	}
+	if plugin.StatusPage {
+		a.AddError(plugin.processStatusPage(ctx, a))
+	}
+	plugin.processTokenFailovers(a)
+	if plugin.RateLimits && !plugin.isGitea() {
+		a.AddError(plugin.processRateLimits(ctx, client, a))
+	}
+	fields := make(map[string]interface{})
+	fields["rate_limit_cost"] = plugin.rateLimitUsage.total()
+	if plugin.Backoff {
+		fields["backoff_level"] = plugin.backoffState.complete()
+	}
+	if plugin.gatherInterval > 0 {
+		gatherDuration := time.Since(gatherStart)
+		fields["gather_duration_seconds"] = gatherDuration.Seconds()
+		fields["interval_overrun"] = gatherDuration > plugin.gatherInterval
+		fields["skipped_repos"] = skippedRepos
+	}
+	a.AddCounter("github_gather", fields, make(map[string]string))
+	if plugin.gatherSummary != nil {
+		plugin.Log.Info(plugin.gatherSummary.line(len(repos), skippedRepos, plugin.rateLimitUsage.total()))
+	}
+	plugin.saveStateFile()
+	return nil
+}
 
-	viewTimestamp := time.Time{}
-	var totalViews int
-	var uniqueViews int
+// processRepos processes the given repos using up to the configured number of concurrently processed repos. The number
+// of repos skipped (due to an exhausted unauthenticated rate limit or an overrun gather interval) is returned.
+func (plugin *GitHub) processRepos(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repos []string, gatherStart time.Time) int {
+	workers := plugin.MaxConcurrentRepos
+	if workers < 1 || plugin.Anonymous {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	skipped := 0
+	repos = plugin.rotateAnonymousRepos(repos)
+	defer func() { plugin.advanceAnonymousRepos(len(repos), len(repos)-skipped) }()
+	for i, repo := range repos {
+		semaphore <- struct{}{}
+		if plugin.anonymousBudgetExhausted(i, time.Now()) {
+			plugin.Log.Warnf("Skipping %d remaining repos due to exhausted unauthenticated rate limit", len(repos)-i)
+			<-semaphore
+			skipped = len(repos) - i
+			break
+		}
+		if plugin.gatherInterval > 0 && time.Since(gatherStart) > plugin.gatherInterval {
+			plugin.Log.Warnf("Skipping %d remaining repos due to gather exceeding the interval of %s", len(repos)-i, plugin.GatherInterval)
+			<-semaphore
+			skipped = len(repos) - i
+			break
+		}
+		wg.Add(1)
+		go func(repo string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if plugin.CircuitFailures <= 0 {
+				err := plugin.processRepo(ctx, client, a, repo)
+				plugin.recordRepoSummary(err)
+				a.AddError(err)
+				return
+			}
+			allowed, skip := plugin.circuitBreaker.allow(repo)
+			if !allowed {
+				tags := make(map[string]string)
+				tags["github_repo"] = repo
+				fields := make(map[string]interface{})
+				fields["open"] = true
+				fields["remaining_skipped_gathers"] = skip
+				a.AddCounter("github_circuit", fields, tags)
+				return
+			}
+			err := plugin.processRepo(ctx, client, a, repo)
+			plugin.recordRepoSummary(err)
+			if plugin.circuitBreaker.record(repo, err, plugin.CircuitFailures, plugin.CircuitGathers) {
+				plugin.Log.Errorf("Skipping repo %s for the next %d gathers after %d consecutive failures", repo, plugin.CircuitGathers, plugin.CircuitFailures)
+			}
+			a.AddError(err)
+		}(repo)
+	}
+	wg.Wait()
+	return skipped
+}
 
-	if plugin.AccessToken != "" {
-		repoTrafficViews, _, err := client.Repositories.ListTrafficViews(ctx, repoOwner, repoName, &githubApi.TrafficBreakdownOptions{Per: "day"})
+func (plugin *GitHub) processRepo(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string) error {
+	if plugin.Debug {
+		plugin.Log.Infof("Processing repo: %s", repo)
+	}
+	repoOwner, repoName, err := plugin.splitRepoId(repo)
+	if err != nil {
+		return err
+	}
+	prefetched := plugin.takePrefetchedRepo(repo)
+	var repoInfo *githubApi.Repository
+	var prefetchedReleases []*githubApi.RepositoryRelease
+	if prefetched != nil {
+		repoInfo = prefetched.info
+		prefetchedReleases = prefetched.releases
+	} else {
+		repoInfo, err = plugin.getRepository(ctx, client, repoOwner, repoName)
 		if err != nil {
 			return err
 		}
-		for _, repoTrafficView := range repoTrafficViews.Views {
-			if repoTrafficView.Timestamp.After(viewTimestamp) {
-				viewTimestamp = repoTrafficView.Timestamp.Time
-				totalViews = repoTrafficView.GetCount()
-				uniqueViews = repoTrafficView.GetUniques()
-			}
+	}
+	state, known := plugin.getRepoState(repo)
+	sizeDelta := 0
+	if known {
+		if plugin.RepoEvents {
+			plugin.processRepoEvents(a, repo, state, repoInfo)
 		}
+		sizeDelta = repoInfo.GetSize() - state.Size
+	}
+	plugin.updateRepoState(state, repoInfo)
+	throttled := plugin.rateLimitThrottled()
+	if throttled {
+		plugin.Log.Warnf("Skipping releases, traffic and optional stats of repo %s due to remaining rate limit below %d", repo, plugin.RateLimitThreshold)
 	}
 	tags := make(map[string]string)
 	tags["github_repo"] = repo
 	fields := make(map[string]interface{})
-	fields["forks_count"] = repoInfo.ForksCount
-	fields["stargazers_count"] = repoInfo.StargazersCount
-	fields["subscribers_count"] = repoInfo.SubscribersCount
-	fields["total_download_count"] = totalDownloadCount
-	fields["total_views"] = totalViews
-	fields["unique_views"] = uniqueViews
-	a.AddCounter("github_info", fields, tags)
+	now := time.Now()
+	if plugin.collectorEnabled(collectorInfo) {
+		fields["forks_count"] = repoInfo.ForksCount
+		fields["stargazers_count"] = repoInfo.StargazersCount
+		fields["subscribers_count"] = repoInfo.SubscribersCount
+		fields["size_kb"] = repoInfo.GetSize()
+		fields["size_delta_kb"] = sizeDelta
+	}
+	if plugin.collectorEnabled(collectorReleases) && !throttled && plugin.collectorDue(repo, collectorReleases, now) {
+		err = plugin.processReleases(ctx, client, a, repo, repoOwner, repoName, prefetchedReleases, fields)
+		if err != nil {
+			return err
+		}
+		plugin.collectorDone(repo, collectorReleases, now)
+	}
+	if plugin.collectorEnabled(collectorTraffic) && !throttled && plugin.collectorDue(repo, collectorTraffic, now) {
+		err = plugin.processTraffic(ctx, client, a, repo, repoOwner, repoName, repoInfo, state, fields)
+		if err != nil {
+			return err
+		}
+		plugin.collectorDone(repo, collectorTraffic, now)
+	}
+	if len(fields) > 0 {
+		a.AddCounter("github_info", fields, tags)
+	}
+	if throttled {
+		return nil
+	}
+	if formula, mapped := plugin.HomebrewFormulae[repo]; mapped {
+		err = plugin.processHomebrew(ctx, a, repo, formula)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.isGitea() || plugin.Anonymous {
+		return nil
+	}
+	if plugin.Referrers {
+		err = plugin.processReferrers(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.PopularPaths {
+		err = plugin.processPopularPaths(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.WorkflowSchedules {
+		err = plugin.processWorkflowSchedules(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.WorkflowConcurrency || plugin.WorkflowReruns {
+		// the window's workflow runs are listed once for all workflow run stats
+		windowRuns, err := plugin.listWindowWorkflowRuns(ctx, client, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+		if plugin.WorkflowConcurrency {
+			plugin.processWorkflowConcurrency(a, repo, windowRuns)
+		}
+		if plugin.WorkflowReruns {
+			plugin.processWorkflowReruns(a, repo, windowRuns)
+		}
+	}
+	if plugin.Environments {
+		err = plugin.processEnvironments(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.Deployments {
+		err = plugin.processDeployments(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.TagProtection {
+		err = plugin.processTagProtection(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	var recentIssues []*githubApi.Issue
+	if plugin.recentIssuesRequired() {
+		recentIssues, err = plugin.listRecentIssues(ctx, client, repoOwner, repoName, now.Add(-plugin.window))
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.IssueTriage {
+		err = plugin.processIssueTriage(ctx, client, a, repo, repoOwner, repoName, recentIssues)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.IssueForms {
+		plugin.processIssueForms(a, repo, recentIssues)
+	}
+	if plugin.ClassicProjects {
+		err = plugin.processRepoClassicProjects(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.Rulesets {
+		err = plugin.processRepoRulesets(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.Submodules {
+		err = plugin.processSubmodules(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.Activity {
+		err = plugin.processActivity(ctx, client, a, repo, repoOwner, repoName, recentIssues)
+		if err != nil {
+			return err
+		}
+	}
+	codeowners := plugin.Codeowners && repoInfo.GetOwner().GetType() == "Organization"
+	var openPullRequests []*githubApi.PullRequest
+	if plugin.DependencyPullRequests || plugin.PullRequestBranches || codeowners {
+		// the open pull requests are listed once for all pull request stats
+		openPullRequests, err = plugin.listOpenPullRequests(ctx, client, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.DependencyPullRequests {
+		plugin.processDependencyPullRequests(a, repo, openPullRequests)
+	}
+	if plugin.PullRequestBranches {
+		plugin.processPullRequestBranches(a, repo, repoInfo.GetDefaultBranch(), openPullRequests)
+	}
+	if plugin.MergeConflicts {
+		err = plugin.processMergeConflicts(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	if codeowners {
+		err = plugin.processCodeowners(ctx, client, a, repo, repoOwner, repoName, openPullRequests)
+		if err != nil {
+			return err
+		}
+	}
+	if len(plugin.ActivityPaths) > 0 {
+		err = plugin.processPathActivity(ctx, client, a, repo, repoOwner, repoName, recentIssues)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.StatusContexts {
+		err = plugin.processStatusContexts(ctx, client, a, repo, repoOwner, repoName, repoInfo)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.ForkConversion {
+		err = plugin.processForkConversion(ctx, client, a, repo, repoOwner, repoName, recentIssues)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.StargazerLocations && plugin.collectorDue(repo, collectorStargazerLocations, now) {
+		err = plugin.processStargazerLocations(ctx, client, a, repo, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+		plugin.collectorDone(repo, collectorStargazerLocations, now)
+	}
+	if len(plugin.GraphQLQueries) > 0 && !plugin.isGitea() && !plugin.Anonymous {
+		scopeTags := map[string]string{"github_repo": repo}
+		scopeVariables := map[string]interface{}{"owner": repoOwner, "name": repoName, "repo": repo}
+		err = plugin.processGraphQLQueries(ctx, client, a, graphQLQueryScopeRepo, scopeTags, scopeVariables)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -139,25 +1052,85 @@ func (plugin *GitHub) getClient(ctx context.Context) (*githubApi.Client, error)
 	if plugin.Debug {
 		plugin.Log.Debug("Creating GitHub client...")
 	}
-	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		ResponseHeaderTimeout: time.Duration(plugin.Timeout) * time.Second,
+	// the client for the non-API requests (status page, Homebrew API and release mirrors) is shared by all these requests
+	plugin.externalClient = &http.Client{
+		Transport: plugin.newTransport(),
+		Timeout:   time.Duration(plugin.Timeout) * time.Second,
+	}
+	// the app's private key is parsed once and the app client is shared by the app and installation token requests
+	appClient, err := plugin.createAppClient()
+	if err != nil {
+		return nil, err
 	}
+	plugin.appClient = appClient
 	httpClient := &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(plugin.Timeout) * time.Second,
+		Transport: plugin.newTransport(),
 	}
-	if plugin.AccessToken != "" {
+	// if enabled, the retrying transport applies the timeout per attempt (otherwise the retry delays would count
+	// towards it)
+	if plugin.Retries <= 0 {
+		httpClient.Timeout = time.Duration(plugin.Timeout) * time.Second
+	}
+	err = plugin.resolveAccessTokens()
+	if err != nil {
+		return nil, err
+	}
+	if len(plugin.AccessTokens) > 0 {
+		if plugin.Debug {
+			plugin.Log.Debugf("Using %d access tokens with %s rotation...", len(plugin.AccessTokens), plugin.TokenRotation)
+		}
+		httpClient.Transport = &rotatingTransport{base: httpClient.Transport, plugin: plugin}
+	} else if !plugin.AccessToken.Empty() {
+		if plugin.Debug {
+			if plugin.BackupAccessToken.Empty() {
+				plugin.Log.Debug("Using access token...")
+			} else {
+				plugin.Log.Debug("Using access token with backup access token...")
+			}
+		}
+		httpClient.Transport = &failoverTransport{base: httpClient.Transport, plugin: plugin}
+	} else if plugin.AccessTokenFile != "" {
+		if plugin.Debug {
+			plugin.Log.Debugf("Using access token file '%s'...", plugin.AccessTokenFile)
+		}
+		httpClient.Transport = &fileTokenTransport{base: httpClient.Transport, plugin: plugin}
+	} else if plugin.appAuthentication() {
+		if plugin.Debug {
+			plugin.Log.Debugf("Using app installation %d...", plugin.InstallationID)
+		}
+		httpClient.Transport = &installationTransport{base: httpClient.Transport, plugin: plugin}
+	}
+	if plugin.Retries > 0 {
+		httpClient.Transport = &retryingTransport{base: httpClient.Transport, plugin: plugin}
+	}
+	if plugin.CacheDir != "" {
+		err := os.MkdirAll(plugin.CacheDir, 0o700)
+		if err != nil {
+			return nil, fmt.Errorf("github: Failed to create cache directory '%s': %v", plugin.CacheDir, err)
+		}
+		httpClient.Transport = &cachingTransport{base: httpClient.Transport, plugin: plugin}
+	}
+	httpClient.Transport = &observingTransport{base: httpClient.Transport, plugin: plugin}
+	return plugin.newAPIClient(httpClient)
+}
+
+func (plugin *GitHub) newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: time.Duration(plugin.Timeout) * time.Second,
+	}
+}
+
+func (plugin *GitHub) newAPIClient(httpClient *http.Client) (*githubApi.Client, error) {
+	if plugin.isGitea() {
 		if plugin.Debug {
-			plugin.Log.Debug("Using oauth2 access token...")
+			plugin.Log.Debugf("Using %s API base URL: '%s'...", plugin.Flavor, plugin.APIBaseURL)
 		}
-		token := &oauth2.Token{AccessToken: plugin.AccessToken}
-		tokenSource := oauth2.StaticTokenSource(token)
-		httpClient = oauth2.NewClient(ctx, tokenSource)
+		return plugin.newGiteaClient(httpClient)
 	}
 	if plugin.APIBaseURL != "" {
 		if plugin.Debug {
-			plugin.Log.Debug("Using API base URL: '%s'...", plugin.APIBaseURL)
+			plugin.Log.Debugf("Using API base URL: '%s'...", plugin.APIBaseURL)
 		}
 		return githubApi.NewEnterpriseClient(plugin.APIBaseURL, "", httpClient)
 	}
@@ -8,12 +8,27 @@
 package github
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/hdecarne-github/github-telegraf-plugin/plugins/inputs/github/githubtest"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -23,33 +38,2694 @@ func TestInit(t *testing.T) {
 	require.NotNil(t, plugin)
 }
 
+func TestCheckPatterns(t *testing.T) {
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_*", "org_name/[a-m]*"}
+	plugin.AssetExclude = []string{"*.sig", "checksums.txt"}
+	require.NoError(t, plugin.checkConfig())
+	plugin.Repos = []string{"repo_owner/repo_["}
+	require.Error(t, plugin.checkConfig())
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.AssetExclude = []string{"*.sig", "[checksums"}
+	require.Error(t, plugin.checkConfig())
+	plugin.AssetExclude = []string{}
+	plugin.ReposExclude = []string{"repo_owner/\\"}
+	require.Error(t, plugin.checkConfig())
+}
+
 func TestSampleConfig(t *testing.T) {
 	plugin := NewGitHub()
-	sampleConfig := plugin.SampleConfig()
-	require.NotNil(t, sampleConfig)
+	sampleConfig := plugin.SampleConfig()
+	require.NotNil(t, sampleConfig)
+}
+
+func TestDescription(t *testing.T) {
+	plugin := NewGitHub()
+	description := plugin.Description()
+	require.NotNil(t, description)
+}
+
+func TestGather1(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 26))
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 614))
+	require.True(t, a.HasPoint("github_info", tags, "unique_views", 237))
+	require.True(t, a.HasPoint("github_info", tags, "total_clones", 26))
+	require.True(t, a.HasPoint("github_info", tags, "unique_clones", 9))
+	require.True(t, a.HasPoint("github_info", tags, "created_at", int64(1609459200)))
+	require.True(t, a.HasPoint("github_info", tags, "traffic_days_available", 14))
+}
+
+func TestGatherCollectors(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Collectors = []string{"info"}
+	plugin.LatestRelease = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "size_kb", 1024))
+	require.False(t, a.HasField("github_info", "total_download_count"))
+	require.False(t, a.HasField("github_info", "total_views"))
+	require.False(t, a.HasMeasurement("github_latest_release"))
+	require.EqualValues(t, 1, testServerHandler.RateLimitUsed)
+
+	plugin.Collectors = []string{"traffic"}
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 614))
+	require.False(t, a.HasField("github_info", "size_kb"))
+
+	plugin.Collectors = []string{"info", "stars"}
+	require.Error(t, plugin.Gather(&a))
+}
+
+func TestGatherCollectorIntervals(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.TrafficInterval = "1d"
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 614))
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 26))
+	require.False(t, a.HasField("github_info", "total_views"))
+	plugin.collectorRuns["repo_owner/repo_name:traffic"] = time.Now().Add(-24*time.Hour + 30*time.Second)
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 614))
+
+	plugin.ReleasesInterval = "daily"
+	require.Error(t, plugin.Gather(&a))
+}
+
+func TestTrafficDaysAvailable(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, 1, trafficDaysAvailable(now.Add(-time.Hour), now))
+	require.Equal(t, 1, trafficDaysAvailable(now.Add(time.Hour), now))
+	require.Equal(t, 3, trafficDaysAvailable(time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC), now))
+	require.Equal(t, 14, trafficDaysAvailable(time.Date(2023, 3, 8, 0, 0, 0, 0, time.UTC), now))
+}
+
+func TestGatherSizeDelta(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_delta_kb", 0))
+
+	plugin.repoStates["repo_owner/repo_name"].Size = 1000
+	a.ClearMetrics()
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_delta_kb", 24))
+}
+
+func TestStarsPerUniqueView(t *testing.T) {
+	plugin := NewGitHub()
+	state := &repoState{}
+	now := time.Date(2022, 10, 24, 12, 0, 0, 0, time.UTC)
+	views := []*githubApi.TrafficData{
+		{Timestamp: &githubApi.Timestamp{Time: time.Date(2022, 10, 22, 0, 0, 0, 0, time.UTC)}, Uniques: githubApi.Int(100)},
+		{Timestamp: &githubApi.Timestamp{Time: time.Date(2022, 10, 23, 0, 0, 0, 0, time.UTC)}, Uniques: githubApi.Int(100)},
+		{Timestamp: &githubApi.Timestamp{Time: time.Date(2022, 10, 24, 0, 0, 0, 0, time.UTC)}, Uniques: githubApi.Int(200)},
+	}
+	require.Equal(t, 0.0, plugin.starsPerUniqueView(state, 10, views, now.Add(-24*time.Hour)))
+	require.Equal(t, 0.02, plugin.starsPerUniqueView(state, 16, views, now))
+	require.Equal(t, 0.03, plugin.starsPerUniqueView(state, 19, views, now.Add(time.Hour)))
+	require.Len(t, state.StarSamples, 2)
+	require.Equal(t, 0.0, plugin.starsPerUniqueView(state, 16, views, now.Add(starSampleWindow+time.Hour)))
+}
+
+func TestReleaseDownloadsPerDay(t *testing.T) {
+	publishedAt := time.Date(2022, 10, 14, 0, 0, 0, 0, time.UTC)
+	release := &githubApi.RepositoryRelease{
+		PublishedAt: &githubApi.Timestamp{Time: publishedAt},
+		Assets: []*githubApi.ReleaseAsset{
+			{DownloadCount: githubApi.Int(15)},
+			{DownloadCount: githubApi.Int(5)},
+		},
+	}
+	plugin := NewGitHub()
+	require.Equal(t, 2.0, plugin.releaseDownloadsPerDay(release, publishedAt.Add(10*24*time.Hour)))
+	require.Equal(t, 20.0, plugin.releaseDownloadsPerDay(release, publishedAt.Add(time.Hour)))
+}
+
+func TestParseWindow(t *testing.T) {
+	window, err := parseWindow("7d")
+	require.NoError(t, err)
+	require.Equal(t, 7*24*time.Hour, window)
+	window, err = parseWindow("2w")
+	require.NoError(t, err)
+	require.Equal(t, 14*24*time.Hour, window)
+	window, err = parseWindow("36h")
+	require.NoError(t, err)
+	require.Equal(t, 36*time.Hour, window)
+	_, err = parseWindow("xd")
+	require.Error(t, err)
+	_, err = parseWindow("0d")
+	require.Error(t, err)
+}
+
+func TestGatherTokenFailover(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("revoked_token"))
+	plugin.BackupAccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	require.True(t, a.HasPoint("github_auth_event", map[string]string{"event": "token_failover"}, "current", "backup_access_token"))
+
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	require.False(t, a.HasMeasurement("github_auth_event"))
+	plugin.AccessToken = config.Secret{}
+	require.EqualError(t, plugin.Gather(&testutil.Accumulator{}), "github: backup_access_token requires access_token")
+}
+
+func TestGatherAccessTokenSecret(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("@{store:github_token}"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	// unresolvable secret store reference
+	require.Error(t, a.GatherError(plugin.Gather))
+	var resolved int32
+	resolvers := map[string]telegraf.ResolveFunc{
+		"@{store:github_token}": func() ([]byte, bool, error) {
+			atomic.AddInt32(&resolved, 1)
+			return []byte("secret_token"), true, nil
+		},
+	}
+	require.NoError(t, plugin.AccessToken.Link(resolvers))
+	require.NoError(t, a.GatherError(plugin.Gather))
+	// the token is resolved once on client creation and then for every request
+	require.Greater(t, atomic.LoadInt32(&resolved), int32(2))
+	require.True(t, a.HasField("github_info", "traffic_days_available"))
+}
+
+func TestGatherAccessTokenFile(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	accessTokenFile := filepath.Join(t.TempDir(), "token")
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessTokenFile = accessTokenFile
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	// missing token file
+	require.Error(t, a.GatherError(plugin.Gather))
+	require.NoError(t, os.WriteFile(accessTokenFile, []byte("secret_token\n"), 0600))
+	a.ClearMetrics()
+	a.Errors = nil
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Empty(t, a.Errors)
+	require.True(t, a.HasField("github_info", "traffic_days_available"))
+
+	// rotated token
+	require.NoError(t, os.WriteFile(accessTokenFile, []byte("revoked_token\n"), 0600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(accessTokenFile, modTime, modTime))
+	require.Error(t, a.GatherError(plugin.Gather))
+	require.Equal(t, "revoked_token", plugin.tokenFile.token)
+
+	// unreadable token file keeps the previous token
+	require.NoError(t, os.Remove(accessTokenFile))
+	token, err := plugin.currentFileToken()
+	require.NoError(t, err)
+	require.Equal(t, "revoked_token", token)
+
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherTokenRotation(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessTokens = []config.Secret{config.NewSecret([]byte("exhausted_token")), config.NewSecret([]byte("secret_token"))}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Empty(t, a.Errors)
+	require.True(t, a.HasMeasurement("github_info"))
+	require.True(t, a.HasField("github_info", "traffic_days_available"))
+	require.True(t, plugin.tokenRotation.exhausted("core", 0, time.Now()))
+	require.Equal(t, 1, plugin.tokenRotation.active)
+
+	plugin.TokenRotation = "random"
+	require.Error(t, a.GatherError(plugin.Gather))
+	plugin.TokenRotation = tokenRotationRoundRobin
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestTokenRotation(t *testing.T) {
+	rotation := &tokenRotation{}
+	require.Equal(t, 0, rotation.next(tokenRotationRoundRobin, "core", 3))
+	require.Equal(t, 1, rotation.next(tokenRotationRoundRobin, "core", 3))
+	response := &http.Response{Header: http.Header{}}
+	response.Header.Set("X-RateLimit-Limit", "5000")
+	response.Header.Set("X-RateLimit-Remaining", "0")
+	response.Header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	rotation.observe("core", 2, 3, response)
+	require.Equal(t, "15000", response.Header.Get("X-RateLimit-Limit"))
+	require.Equal(t, "10000", response.Header.Get("X-RateLimit-Remaining"))
+	require.Equal(t, "5000", response.Header.Get("X-RateLimit-Used"))
+	require.Equal(t, 0, rotation.next(tokenRotationRoundRobin, "core", 3))
+	require.Equal(t, 1, rotation.next(tokenRotationRoundRobin, "core", 3))
+	require.Equal(t, 0, rotation.next(tokenRotationRoundRobin, "core", 3))
+	rotation.active = 2
+	require.Equal(t, 2, rotation.next(tokenRotationRateLimit, "search", 3))
+	require.Equal(t, 0, rotation.next(tokenRotationRateLimit, "core", 3))
+	require.Equal(t, 0, rotation.next(tokenRotationRateLimit, "core", 3))
+}
+
+func TestGatherRateLimitCost(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, RateLimitUsed: 100}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "rate_limit_cost", 2))
+}
+
+func TestGatherIntervalOverrun(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "repo_owner/other_repo"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.GatherInterval = "1h"
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "interval_overrun", false))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "skipped_repos", 0))
+	require.True(t, a.HasMeasurement("github_info"))
+
+	plugin.GatherInterval = "1ns"
+	a.ClearMetrics()
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "interval_overrun", true))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "skipped_repos", 2))
+	require.False(t, a.HasMeasurement("github_info"))
+}
+
+func TestGatherLogSummary(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.LogSummary = true
+	logger := &testutil.CaptureLogger{}
+	plugin.Log = logger
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	var summary map[string]interface{}
+	for _, entry := range logger.Messages() {
+		if strings.HasPrefix(entry.Text, `{"summary":"gather"`) {
+			require.NoError(t, json.Unmarshal([]byte(entry.Text), &summary))
+		}
+	}
+	require.NotNil(t, summary)
+	require.EqualValues(t, 1, summary["repos"])
+	require.EqualValues(t, 1, summary["repos_processed"])
+	require.EqualValues(t, 0, summary["repos_failed"])
+	require.Greater(t, summary["calls"], 0.0)
+	require.Empty(t, summary["errors"])
+}
+
+func TestSummaryEndpoint(t *testing.T) {
+	endpoint := func(method string, path string) string {
+		request := httptest.NewRequest(method, "http://localhost"+path, nil)
+		return summaryEndpoint(request)
+	}
+	require.Equal(t, "GET /repos/:owner/:repo/traffic/views", endpoint("GET", "/api/v3/repos/repo_owner/repo_name/traffic/views"))
+	require.Equal(t, "GET /repos/:owner/:repo/actions/workflows/:id/runs", endpoint("GET", "/repos/repo_owner/repo_name/actions/workflows/42/runs"))
+	require.Equal(t, "GET /orgs/:org/repos", endpoint("GET", "/orgs/org_name/repos"))
+	require.Equal(t, "POST /graphql", endpoint("POST", "/api/graphql"))
+}
+
+func TestGatherBackoff(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, Incident: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Backoff = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	gather := func() (*testutil.Accumulator, error) {
+		a := &testutil.Accumulator{}
+		return a, a.GatherError(plugin.Gather)
+	}
+
+	// first failing gather: level 1 (skip 1)
+	a, err := gather()
+	require.Error(t, err)
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "backoff_level", 1))
+	a, err = gather()
+	require.NoError(t, err)
+	require.False(t, a.HasMeasurement("github_info"))
+	// second failing gather: level 2 (skip 3)
+	_, err = gather()
+	require.Error(t, err)
+	testServerHandler.Incident = false
+	for i := 0; i < 3; i++ {
+		a, err = gather()
+		require.NoError(t, err)
+		require.True(t, a.HasPoint("github_gather", map[string]string{}, "backoff_level", 2))
+		require.False(t, a.HasMeasurement("github_info"))
+	}
+	// recovered
+	a, err = gather()
+	require.NoError(t, err)
+	require.True(t, a.HasMeasurement("github_info"))
+	require.True(t, a.HasPoint("github_gather", map[string]string{}, "backoff_level", 0))
+}
+
+func TestGatherRetries(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, TransientFailures: 2}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Retries = 2
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "total_download_count", 26))
+
+	testServerHandler.TransientFailures = 3
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherRetryExceedingTimeout(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, TransientFailures: 1, RetryAfter: "2"}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Timeout = 1
+	plugin.Retries = 1
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "total_download_count", 26))
+}
+
+func TestGatherCircuit(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "repo_owner/deleted_repo"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.CircuitFailures = 2
+	plugin.CircuitGathers = 2
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	tags := map[string]string{"github_repo": "repo_owner/deleted_repo"}
+	gather := func() (*testutil.Accumulator, error) {
+		a := &testutil.Accumulator{}
+		return a, a.GatherError(plugin.Gather)
+	}
+
+	for i := 0; i < 2; i++ {
+		a, err := gather()
+		require.Error(t, err)
+		require.False(t, a.HasMeasurement("github_circuit"))
+	}
+	for skip := 1; skip >= 0; skip-- {
+		a, err := gather()
+		require.NoError(t, err)
+		require.True(t, a.HasPoint("github_circuit", tags, "remaining_skipped_gathers", skip))
+		require.True(t, a.HasMeasurement("github_info"))
+	}
+	// a single failure re-opens the circuit
+	_, err := gather()
+	require.Error(t, err)
+	a, err := gather()
+	require.NoError(t, err)
+	require.True(t, a.HasPoint("github_circuit", tags, "open", true))
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	response := func(headers ...string) *http.Response {
+		header := make(http.Header)
+		for i := 0; i < len(headers); i += 2 {
+			header.Set(headers[i], headers[i+1])
+		}
+		return &http.Response{StatusCode: http.StatusForbidden, Header: header}
+	}
+	require.Equal(t, time.Second, retryDelay(response(), 0, now))
+	require.Equal(t, 4*time.Second, retryDelay(response(), 2, now))
+	require.Equal(t, 30*time.Second, retryDelay(response("Retry-After", "30"), 2, now))
+	require.Equal(t, 90*time.Second, retryDelay(response("Retry-After", now.Add(90*time.Second).Format(http.TimeFormat)), 0, now))
+	require.Equal(t, time.Duration(0), retryDelay(response("Retry-After", now.Add(-time.Minute).Format(http.TimeFormat)), 0, now))
+	require.Equal(t, 10*time.Minute, retryDelay(response("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10)), 0, now))
+}
+
+func TestRateLimitUsage(t *testing.T) {
+	usage := newRateLimitUsage()
+	response := func(resource string, used int) *http.Response {
+		header := make(http.Header)
+		header.Set("X-RateLimit-Resource", resource)
+		header.Set("X-RateLimit-Used", strconv.Itoa(used))
+		return &http.Response{Header: header}
+	}
+	usage.update(response("core", 10))
+	usage.update(response("core", 13))
+	usage.update(response("graphql", 50))
+	usage.update(response("core", 2))
+	usage.update(&http.Response{Header: make(http.Header)})
+	require.Equal(t, 1+3+1+2, usage.total())
+}
+
+func TestGatherTrafficSeries(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.TrafficSeries = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, 15, countTrafficSeries(t, &a, "github_traffic_views"))
+	require.Equal(t, 3, countTrafficSeries(t, &a, "github_traffic_clones"))
+
+	// restart with persisted state
+	serialized, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var state pluginState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+	restarted := NewGitHub()
+	restarted.Repos = plugin.Repos
+	restarted.APIBaseURL = plugin.APIBaseURL
+	restarted.AccessToken = plugin.AccessToken
+	restarted.TrafficSeries = true
+	restarted.Log = plugin.Log
+	require.NoError(t, restarted.SetState(state))
+	require.Error(t, restarted.SetState("invalid"))
+
+	// entries already emitted before the restart are not emitted again
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(restarted.Gather))
+	require.Equal(t, 0, countTrafficSeries(t, &a, "github_traffic_views"))
+	require.Equal(t, 0, countTrafficSeries(t, &a, "github_traffic_clones"))
+	// the latest entry is re-emitted as its counts grow
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(restarted.Gather))
+	require.Equal(t, 1, countTrafficSeries(t, &a, "github_traffic_views"))
+	require.Equal(t, 1, countTrafficSeries(t, &a, "github_traffic_clones"))
+}
+
+func TestGatherStateFile(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	newPlugin := func() *GitHub {
+		plugin := NewGitHub()
+		plugin.Repos = []string{"repo_owner/repo_name"}
+		plugin.APIBaseURL = testServer.URL
+		plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+		plugin.TrafficSeries = true
+		plugin.StateFile = stateFile
+		plugin.Log = createDummyLogger()
+		plugin.Debug = testServerHandler.Debug
+		return plugin
+	}
+
+	var a testutil.Accumulator
+
+	plugin := newPlugin()
+	require.NoError(t, plugin.Init())
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, 15, countTrafficSeries(t, &a, "github_traffic_views"))
+	require.FileExists(t, stateFile)
+
+	restarted := newPlugin()
+	require.NoError(t, restarted.Init())
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(restarted.Gather))
+	require.Equal(t, 0, countTrafficSeries(t, &a, "github_traffic_views"))
+}
+
+func TestGatherTrafficExport(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.TrafficExport = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, 15+3, countTrafficExport(t, &a))
+	require.True(t, a.HasPoint("github_traffic_export", map[string]string{"github_repo": "repo_owner/repo_name", "traffic": "clones"}, "date", "2022-10-24"))
+
+	// all entries are re-emitted
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, 15+3, countTrafficExport(t, &a))
+
+	plugin.TrafficBreakdown = "week"
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func countTrafficExport(t *testing.T, a *testutil.Accumulator) int {
+	exports := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_traffic_export" {
+			exports++
+			date, _ := metric.GetField("date")
+			require.Equal(t, metric.Time().UTC().Format("2006-01-02"), date)
+		}
+	}
+	return exports
+}
+
+func countTrafficSeries(t *testing.T, a *testutil.Accumulator, measurement string) int {
+	series := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == measurement {
+			series++
+			if metric.Time().Equal(time.Date(2022, 10, 24, 0, 0, 0, 0, time.UTC)) {
+				count, _ := metric.GetField("count")
+				require.EqualValues(t, map[string]int{"github_traffic_views": 614, "github_traffic_clones": 26}[measurement], count)
+			}
+		}
+	}
+	return series
+}
+
+func TestGatherTrafficBreakdownWeek(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.TrafficBreakdown = "week"
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 7087))
+	require.True(t, a.HasPoint("github_info", tags, "unique_clones", 128))
+
+	plugin.TrafficBreakdown = "month"
+	require.Error(t, plugin.Gather(&a))
+}
+
+func TestReleaseCadence(t *testing.T) {
+	var repoReleases []*githubApi.RepositoryRelease
+	require.NoError(t, json.Unmarshal([]byte(githubtest.RepositoryReleases), &repoReleases))
+	plugin := NewGitHub()
+	var a testutil.Accumulator
+
+	plugin.processReleaseCadence(&a, "repo_owner/repo_name", repoReleases, time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_release_cadence", tags, "releases_last_90d", 3))
+	require.True(t, a.HasPoint("github_release_cadence", tags, "days_since_last_release", 12.0))
+	a.ClearMetrics()
+	plugin.processReleaseCadence(&a, "repo_owner/repo_name", repoReleases, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, a.HasPoint("github_release_cadence", tags, "releases_last_90d", 1))
+	a.ClearMetrics()
+	plugin.processReleaseCadence(&a, "repo_owner/repo_name", nil, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, a.HasPoint("github_release_cadence", tags, "has_release", false))
+	require.False(t, a.HasField("github_release_cadence", "days_since_last_release"))
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	prereleases := []*githubApi.RepositoryRelease{
+		{TagName: githubApi.String("v1.0.0"), PublishedAt: &githubApi.Timestamp{Time: now.Add(-10 * 24 * time.Hour)}},
+		{TagName: githubApi.String("v1.1.0-rc1"), Prerelease: githubApi.Bool(true), PublishedAt: &githubApi.Timestamp{Time: now.Add(-2 * 24 * time.Hour)}},
+	}
+	a.ClearMetrics()
+	plugin.processReleaseCadence(&a, "repo_owner/repo_name", prereleases, now)
+	require.True(t, a.HasPoint("github_release_cadence", tags, "releases_last_90d", 2))
+	require.True(t, a.HasPoint("github_release_cadence", tags, "days_since_last_release", 2.0))
+	plugin.IncludePrereleases = false
+	a.ClearMetrics()
+	plugin.processReleaseCadence(&a, "repo_owner/repo_name", prereleases, now)
+	require.True(t, a.HasPoint("github_release_cadence", tags, "releases_last_90d", 1))
+	require.True(t, a.HasPoint("github_release_cadence", tags, "days_since_last_release", 10.0))
+}
+
+func TestReleaseNotes(t *testing.T) {
+	var repoReleases []*githubApi.RepositoryRelease
+	require.NoError(t, json.Unmarshal([]byte(githubtest.RepositoryReleases), &repoReleases))
+	plugin := NewGitHub()
+	plugin.window = 60 * 24 * time.Hour
+	var a testutil.Accumulator
+
+	plugin.processReleaseNotes(&a, "repo_owner/repo_name", repoReleases, time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_release_notes", tags, "releases", 2))
+	require.True(t, a.HasPoint("github_release_notes", tags, "releases_without_body", 1))
+}
+
+func TestGatherReleaseStats(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ReleaseStats = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.1.0"}
+	require.True(t, a.HasPoint("github_release", tags, "download_count", 18))
+	require.True(t, a.HasPoint("github_release", tags, "asset_count", 6))
+	require.True(t, a.HasPoint("github_release", tags, "published_at", int64(1663632000)))
+	tags["github_release"] = "v1.0.0"
+	require.True(t, a.HasPoint("github_release", tags, "download_count", 0))
+}
+
+func TestGatherLatestRelease(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.LatestRelease = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.2.0"}
+	require.True(t, a.HasPoint("github_latest_release", tags, "latest_release_downloads", 8))
+	require.True(t, a.HasPoint("github_latest_release", tags, "latest_release_published_at", int64(1666224000)))
+	require.True(t, a.HasField("github_latest_release", "latest_release_age_days"))
+}
+
+func TestLatestRelease(t *testing.T) {
+	published := func(day int) *githubApi.Timestamp {
+		return &githubApi.Timestamp{Time: time.Date(2022, 10, day, 0, 0, 0, 0, time.UTC)}
+	}
+	repoReleases := []*githubApi.RepositoryRelease{
+		{TagName: githubApi.String("v1.0.0"), PublishedAt: published(1)},
+		{TagName: githubApi.String("v1.1.0-rc1"), Prerelease: githubApi.Bool(true), PublishedAt: published(2)},
+		{TagName: githubApi.String("v1.1.0"), Draft: githubApi.Bool(true), PublishedAt: published(3)},
+	}
+	plugin := NewGitHub()
+	require.Equal(t, "v1.1.0-rc1", plugin.latestRelease(repoReleases).GetTagName())
+	plugin.IncludePrereleases = false
+	require.Equal(t, "v1.0.0", plugin.latestRelease(repoReleases).GetTagName())
+	require.Nil(t, plugin.latestRelease(repoReleases[2:]))
+}
+
+func TestGatherTagCoverage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.TagCoverage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_tag_coverage", tags, "tags", 5))
+	require.True(t, a.HasPoint("github_tag_coverage", tags, "tags_without_release", 2))
+}
+
+func TestGatherReleaseAssets(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ReleaseAssets = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.2.0", "github_asset": "plugin-linux-amd64.tar.gz"}
+	require.True(t, a.HasPoint("github_release_asset", tags, "download_count", 1))
+	require.True(t, a.HasPoint("github_release_asset", tags, "size", 1048576))
+	tags = map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.1.0", "github_asset": "plugin.spdx.json"}
+	require.True(t, a.HasPoint("github_release_asset", tags, "download_count", 2))
+}
+
+func TestGatherReleaseAssetTypes(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ReleaseAssetTypes = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := func(assetType string) map[string]string {
+		return map[string]string{"github_repo": "repo_owner/repo_name", "asset_type": assetType}
+	}
+	require.True(t, a.HasPoint("github_release_asset_types", tags("archive"), "download_count", 3))
+	require.True(t, a.HasPoint("github_release_asset_types", tags("archive"), "assets", 2))
+	require.True(t, a.HasPoint("github_release_asset_types", tags("signature"), "download_count", 1))
+	require.True(t, a.HasPoint("github_release_asset_types", tags("other"), "download_count", 22))
+	require.False(t, a.HasPoint("github_release_asset_types", tags("installer"), "assets", 0))
+}
+
+func TestReleaseAssetType(t *testing.T) {
+	require.Equal(t, "checksum", releaseAssetType("plugin_1.0.0_checksums.txt", "text/plain"))
+	require.Equal(t, "signature", releaseAssetType("plugin.tar.gz.asc", "application/octet-stream"))
+	require.Equal(t, "installer", releaseAssetType("Plugin-Setup.MSI", "application/octet-stream"))
+	require.Equal(t, "package", releaseAssetType("plugin_1.0.0_amd64.deb", "application/octet-stream"))
+	require.Equal(t, "archive", releaseAssetType("plugin-linux-amd64.tar.gz", "application/octet-stream"))
+	require.Equal(t, "archive", releaseAssetType("plugin-linux-amd64", "application/x-gzip; charset=binary"))
+	require.Equal(t, "other", releaseAssetType("plugin-linux-amd64", "application/octet-stream"))
+}
+
+func TestGatherReleasePages(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, PagedReleases: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 30))
+
+	plugin.MaxReleasePages = 1
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 26))
+}
+
+func TestGatherExcludePrereleases(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, PagedReleases: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.IncludePrereleases = false
+	plugin.IncludeDrafts = false
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "total_download_count", 26))
+}
+
+func TestGatherAssetPatterns(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AssetExclude = []string{"*.sig", "*.spdx.json"}
+	plugin.ReleaseStats = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "total_download_count", 23))
+	require.True(t, a.HasPoint("github_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.2.0"}, "download_count", 7))
+
+	plugin.AssetInclude = []string{"plugin-linux-*"}
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.2.0"}, "download_count", 1))
+}
+
+func TestGatherBotAssets(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ExcludeBotAssets = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 23))
+	require.True(t, a.HasPoint("github_info", tags, "bot_download_count", 3))
+}
+
+func TestGatherReleaseMirrors(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	mirrorServer := httptest.NewServer(http.HandlerFunc(func(out http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodHead || request.URL.Path != "/repo_name/v1.2.0/plugin-linux-amd64.tar.gz" {
+			out.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mirrorServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AssetInclude = []string{"plugin-linux-*"}
+	plugin.ReleaseMirrors = []string{mirrorServer.URL + "/{repo}/{tag}/{asset}"}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_release": "v1.2.0", "mirror": mirrorServer.Listener.Addr().String()}
+	require.True(t, a.HasPoint("github_release_mirror", tags, "assets", 2))
+	require.True(t, a.HasPoint("github_release_mirror", tags, "present_assets", 1))
+	require.True(t, a.HasPoint("github_release_mirror", tags, "missing_assets", 1))
+	require.True(t, a.HasPoint("github_release_mirror", tags, "in_sync", false))
+}
+
+func TestGatherHomebrew(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.HomebrewAPIURL = testServer.URL + "/api/homebrew/"
+	plugin.HomebrewFormulae = map[string]string{"repo_owner/repo_name": "repo_name"}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "formula": "repo_name"}
+	require.True(t, a.HasPoint("github_homebrew", tags, "installs_30d", 123))
+	require.True(t, a.HasPoint("github_homebrew", tags, "installs_365d", 1500))
+	require.True(t, a.HasPoint("github_homebrew", tags, "installs_on_request_90d", 350))
+	require.True(t, a.HasPoint("github_homebrew", tags, "build_errors_30d", 2))
+
+	plugin.HomebrewFormulae = map[string]string{"repo_owner/repo_name": "unknown"}
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherGitea(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Flavor = "gitea"
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.IssueTriage = true
+	plugin.ActionsUsage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_info" {
+			stars, _ := metric.GetField("stargazers_count")
+			require.EqualValues(t, 42, stars)
+			subscribers, _ := metric.GetField("subscribers_count")
+			require.EqualValues(t, 5, subscribers)
+		}
+	}
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 26))
+	require.False(t, a.HasMeasurement("github_issue_triage"))
+	require.False(t, a.HasMeasurement("github_actions_usage"))
+
+	plugin.APIBaseURL = ""
+	require.Error(t, plugin.Gather(&a))
+	plugin.Flavor = "gitlab"
+	require.Error(t, plugin.Gather(&a))
+}
+
+func TestGatherMetricType(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	for metricType, valueType := range map[string]telegraf.ValueType{"counter": telegraf.Counter, "gauge": telegraf.Gauge, "untyped": telegraf.Untyped} {
+		plugin.MetricType = metricType
+
+		var a testutil.Accumulator
+
+		require.NoError(t, a.GatherError(plugin.Gather))
+		for _, metric := range a.GetTelegrafMetrics() {
+			if metric.Name() == "github_info" {
+				require.Equal(t, valueType, metric.Type())
+			}
+		}
+	}
+	plugin.MetricType = "histogram"
+	require.Error(t, plugin.Gather(&testutil.Accumulator{}))
+}
+
+func TestGatherRateLimits(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, RateLimit: 5000}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.RateLimits = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"resource": "core"}
+	require.True(t, a.HasPoint("github_rate_limit", tags, "limit", 5000))
+	remaining, _ := a.IntField("github_rate_limit", "remaining")
+	require.Less(t, remaining, 5000)
+	resetSeconds, _ := a.IntField("github_rate_limit", "reset_seconds")
+	require.InDelta(t, 3600, resetSeconds, 5)
+
+	a.ClearMetrics()
+	plugin.RateLimitsSource = "endpoint"
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_rate_limit", tags, "remaining", 4999))
+	require.True(t, a.HasPoint("github_rate_limit", map[string]string{"resource": "search"}, "remaining", 18))
+	require.True(t, a.HasPoint("github_rate_limit", map[string]string{"resource": "graphql"}, "limit", 5000))
+	rateLimitMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_rate_limit" {
+			rateLimitMetrics++
+		}
+	}
+	require.Equal(t, 3, rateLimitMetrics)
+
+	plugin.RateLimitsSource = "response"
+	require.Error(t, plugin.Gather(&a))
+}
+
+func TestGatherRateLimitThreshold(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, RateLimit: 5000}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.OrgWebhooks = true
+	plugin.RateLimitThreshold = 5000
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_info", tags, "size_kb", 1024))
+	require.False(t, a.HasField("github_info", "total_download_count"))
+	require.False(t, a.HasField("github_info", "total_views"))
+	require.False(t, a.HasMeasurement("github_org_webhooks"))
+	require.EqualValues(t, 1, testServerHandler.RateLimitUsed)
+
+	plugin.RateLimitThreshold = 10
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 26))
+	require.True(t, a.HasMeasurement("github_org_webhooks"))
+}
+
+func TestGatherMetricVersion(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	for _, metric := range a.GetTelegrafMetrics() {
+		require.False(t, metric.HasTag("schema_version"), metric.Name())
+	}
+	plugin.MetricVersion = 1
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	for _, metric := range a.GetTelegrafMetrics() {
+		schemaVersion, _ := metric.GetTag("schema_version")
+		require.Equal(t, "1", schemaVersion, metric.Name())
+	}
+	plugin.MetricVersion = latestMetricVersion + 1
+	require.Error(t, plugin.Gather(&testutil.Accumulator{}))
+}
+
+func TestGatherStatusPage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.StatusPage = true
+	plugin.StatusPageURL = testServer.URL + "/api/v2/components.json"
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_status", map[string]string{"component": "API Requests"}, "operational", true))
+	require.True(t, a.HasPoint("github_status", map[string]string{"component": "Actions"}, "status_level", 3))
+	require.True(t, a.HasPoint("github_status", map[string]string{"component": "Packages"}, "status", "degraded_performance"))
+	require.True(t, a.HasPoint("github_status", map[string]string{"component": "Pages"}, "operational", true))
+	statusMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_status" {
+			statusMetrics++
+		}
+	}
+	require.Equal(t, 4, statusMetrics)
+}
+
+func TestGatherReferrers(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Referrers = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_referrers", map[string]string{"github_repo": "repo_owner/repo_name", "referrer": "Google"}, "count", 4))
+	require.True(t, a.HasPoint("github_referrers", map[string]string{"github_repo": "repo_owner/repo_name", "referrer": "github.com"}, "uniques", 1))
+}
+
+func TestGatherPopularPaths(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.PopularPaths = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "path": "/repo_owner/repo_name/blob/main/README.md", "title": "repo_name/README.md at main"}
+	require.True(t, a.HasPoint("github_paths", tags, "count", 98))
+	require.True(t, a.HasPoint("github_paths", tags, "uniques", 48))
+}
+
+func TestInitClient(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	require.NoError(t, plugin.Init())
+	client := plugin.client
+	require.NotNil(t, client)
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Same(t, client, plugin.client)
+
+	invalid := NewGitHub()
+	invalid.Repos = []string{"repo_owner/repo_name"}
+	invalid.APIBaseURL = "://invalid"
+	invalid.Log = createDummyLogger()
+	require.Error(t, invalid.Init())
+	invalid.APIBaseURL = ""
+	invalid.Flavor = "gitea"
+	require.Error(t, invalid.Init())
+}
+
+func TestInitValidateAccess(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "repo_owner/deleted_repo", "org_name/*"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	require.NoError(t, plugin.Init())
+	invisibleRepos, readOnlyRepos, err := plugin.checkRepoAccess(context.Background(), plugin.client)
+	require.NoError(t, err)
+	require.Equal(t, []string{"repo_owner/deleted_repo"}, invisibleRepos)
+	require.Empty(t, readOnlyRepos)
+	require.Equal(t, []string{"read:org"}, plugin.missingScopes(http.Header{"X-Oauth-Scopes": []string{"public_repo"}}))
+	require.Empty(t, plugin.missingScopes(http.Header{}))
+
+	rejected := NewGitHub()
+	rejected.Repos = []string{"repo_owner/repo_name"}
+	rejected.APIBaseURL = testServer.URL
+	rejected.AccessToken = config.NewSecret([]byte("revoked_token"))
+	rejected.Log = createDummyLogger()
+	require.Error(t, rejected.Init())
+	rejected.ValidateAccess = false
+	require.NoError(t, rejected.Init())
+}
+
+func TestGatherDryRun(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.WorkflowSchedules = true
+	plugin.ActivityPaths = []string{"docs"}
+	plugin.AuditLog = true
+	plugin.DryRun = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	require.Len(t, plugin.planRepoCalls("repo_owner/repo_name"), 10)
+	require.Len(t, plugin.planOrgCalls("org_name"), 2)
+	require.NoError(t, plugin.Init())
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, uint64(0), a.NMetrics())
+}
+
+func TestGatherWorkflowSchedules(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.WorkflowSchedules = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_workflow_schedule"))
+	require.True(t, a.HasTag("github_workflow_schedule", "github_workflow"))
+	disabled, ok := a.BoolField("github_workflow_schedule", "disabled_inactivity")
+	require.True(t, ok)
+	require.True(t, disabled)
+	scheduleCount, ok := a.IntField("github_workflow_schedule", "schedule_count")
+	require.True(t, ok)
+	require.Equal(t, 2, scheduleCount)
+	// the dynamic and the removed workflow have no schedules (and must not fail the repo)
+	require.Len(t, a.Errors, 0)
+	schedules := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_workflow_schedule" {
+			schedules++
+		}
+	}
+	require.Equal(t, 1, schedules)
+}
+
+func TestGatherEnvironments(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Environments = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_environment"))
+	requiredReviewers, ok := a.IntField("github_environment", "required_reviewers")
+	require.True(t, ok)
+	require.Equal(t, 2, requiredReviewers)
+	waitTimer, ok := a.IntField("github_environment", "wait_timer")
+	require.True(t, ok)
+	require.Equal(t, 30, waitTimer)
+}
+
+func TestGatherDeployments(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Deployments = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_deployment"))
+	deployments, ok := a.IntField("github_deployment", "deployments")
+	require.True(t, ok)
+	require.Equal(t, 2, deployments)
+	failurePercent, ok := a.FloatField("github_deployment", "failure_percent")
+	require.True(t, ok)
+	require.Equal(t, 50.0, failurePercent)
+	avgDuration, ok := a.FloatField("github_deployment", "avg_duration_seconds")
+	require.True(t, ok)
+	require.Equal(t, 90.0, avgDuration)
+}
+
+func TestGatherTagProtection(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.TagProtection = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_tag_protection", tags, "enabled", true))
+	require.True(t, a.HasPoint("github_tag_protection", tags, "rulesets", 2))
+	require.True(t, a.HasPoint("github_tag_protection", tags, "active_rulesets", 1))
+	require.True(t, a.HasPoint("github_tag_protection", tags, "patterns", 2))
+}
+
+func TestGatherReleaseDigests(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ReleaseDigests = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_release_digest"))
+	replaced, ok := a.BoolField("github_release_digest", "assets_replaced")
+	require.True(t, ok)
+	require.False(t, replaced)
+
+	for key := range plugin.releaseDigests {
+		plugin.releaseDigests[key] = "outdated"
+	}
+	a.ClearMetrics()
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	replaced, ok = a.BoolField("github_release_digest", "assets_replaced")
+	require.True(t, ok)
+	require.True(t, replaced)
+}
+
+func TestGatherReleaseSignatures(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ReleaseSignatures = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_release_signatures"))
+	releases, ok := a.IntField("github_release_signatures", "releases")
+	require.True(t, ok)
+	require.Equal(t, 3, releases)
+	signedReleases, ok := a.IntField("github_release_signatures", "signed_releases")
+	require.True(t, ok)
+	require.Equal(t, 1, signedReleases)
+	sbomReleases, ok := a.IntField("github_release_signatures", "sbom_releases")
+	require.True(t, ok)
+	require.Equal(t, 1, sbomReleases)
+}
+
+func TestGatherActionsUsage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.ActionsUsage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_actions_usage"))
+	require.False(t, a.HasMeasurement("github_info"))
+	linuxMinutes := 0.0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Tags()["github_repo"] == "org_name/repo_name" && metric.Tags()["runner_type"] == "Actions Linux" {
+			minutes, _ := metric.GetField("minutes")
+			linuxMinutes = minutes.(float64)
+		}
+	}
+	require.Equal(t, 150.0, linuxMinutes)
+}
+
+func TestGatherActionsLeaderboard(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.ActionsLeaderboard = 1
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_org": "org_name", "github_repo": "org_name/repo_name"}
+	require.True(t, a.HasPoint("github_actions_leaderboard", tags, "rank", 1))
+	require.True(t, a.HasPoint("github_actions_leaderboard", tags, "minutes", 160.0))
+	require.True(t, a.HasPoint("github_actions_leaderboard", tags, "minutes_share_percent", 80.0))
+	require.False(t, a.HasPoint("github_actions_leaderboard", map[string]string{"github_org": "org_name", "github_repo": "org_name/other_repo"}, "rank", 2))
+
+	plugin.ActionsLeaderboard = 2
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_actions_leaderboard", map[string]string{"github_org": "org_name", "github_repo": "org_name/other_repo"}, "rank", 2))
+}
+
+func TestActionsLeaderboardWithoutMinutes(t *testing.T) {
+	plugin := NewGitHub()
+	plugin.ActionsLeaderboard = 1
+	var a testutil.Accumulator
+
+	usage := &billingUsage{UsageItems: []*billingUsageItem{{Product: "actions", UnitType: "minutes", RepositoryName: "repo_name"}}}
+	plugin.processActionsLeaderboard(&a, "org_name", usage)
+	tags := map[string]string{"github_org": "org_name", "github_repo": "org_name/repo_name"}
+	require.True(t, a.HasPoint("github_actions_leaderboard", tags, "rank", 1))
+	require.False(t, a.HasField("github_actions_leaderboard", "minutes_share_percent"))
+}
+
+func TestGatherCostCenters(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.CostCenters = map[string][]string{"platform": {"@repo_owner/team_slug"}, "other": {"other_owner/*"}}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_info"))
+	require.True(t, a.HasTag("github_info", "cost_center"))
+	require.Equal(t, "platform", a.TagValue("github_info", "cost_center"))
+}
+
+func TestGatherCopilotUsage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.CopilotUsage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_copilot_usage"))
+	copilotMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_copilot_usage" {
+			copilotMetrics++
+		}
+	}
+	require.Equal(t, 6, copilotMetrics)
+	require.True(t, a.HasPoint("github_copilot_usage", map[string]string{"github_org": "org_name", "breakdown": "total"}, "suggestions", 1000))
+	require.True(t, a.HasPoint("github_copilot_usage", map[string]string{"github_org": "org_name", "breakdown": "language", "language": "python"}, "suggestions", 400))
+	require.True(t, a.HasPoint("github_copilot_usage", map[string]string{"github_org": "org_name", "breakdown": "editor", "editor": "vscode"}, "lines_accepted", 800))
+}
+
+func TestGatherAuditLog(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.AuditLog = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_audit_log"))
+	require.True(t, a.HasPoint("github_audit_log", map[string]string{"github_org": "org_name", "category": "repo", "action": "repo.create"}, "events", 2))
+	require.True(t, a.HasPoint("github_audit_log", map[string]string{"github_org": "org_name", "category": "org", "action": "org.update_member"}, "events", 1))
+	require.Equal(t, 24*time.Hour, plugin.auditLogWindow)
+
+	plugin.AuditLogWindow = "forever"
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherIPAllowList(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.IPAllowList = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_ip_allow_list"))
+	require.True(t, a.HasPoint("github_ip_allow_list", map[string]string{"github_org": "org_name"}, "enabled", true))
+	require.True(t, a.HasPoint("github_ip_allow_list", map[string]string{"github_org": "org_name"}, "entries", 2))
+	require.True(t, a.HasPoint("github_ip_allow_list", map[string]string{"github_org": "org_name"}, "active_entries", 1))
+}
+
+func TestGatherSSOCredentials(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.SSOCredentials = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_credential_authorizations"))
+	require.True(t, a.HasPoint("github_credential_authorizations", map[string]string{"github_org": "org_name", "credential_type": "personal access token"}, "credentials", 3))
+	require.True(t, a.HasPoint("github_credential_authorizations", map[string]string{"github_org": "org_name", "credential_type": "personal access token"}, "users", 2))
+}
+
+func TestGatherPATRequests(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.PATRequests = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_pat_requests"))
+	require.True(t, a.HasPoint("github_pat_requests", map[string]string{"github_org": "org_name"}, "pending_requests", 2))
+	oldestRequestAge, ok := a.Int64Field("github_pat_requests", "oldest_request_age_seconds")
+	require.True(t, ok)
+	require.Greater(t, oldestRequestAge, int64(0))
+}
+
+func TestGatherAppPermissions(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.APIBaseURL = testServer.URL
+	plugin.AppID = 1
+	plugin.InstallationID = 1
+	plugin.PrivateKeyPath = createTestPrivateKey(t)
+	plugin.AppPermissions = true
+	plugin.ExpectedAppPermissions = map[string]string{"contents": "write", "metadata": "read"}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_app_permissions"))
+	require.True(t, a.HasPoint("github_app_permissions", map[string]string{"github_org": "org_name", "installation_id": "1"}, "permission_contents", "read"))
+	require.True(t, a.HasPoint("github_app_permissions", map[string]string{"github_org": "org_name", "installation_id": "1"}, "drift", true))
+	require.True(t, a.HasPoint("github_app_permissions", map[string]string{"github_org": "org_name", "installation_id": "1"}, "drifted_permissions", 2))
+}
+
+func TestGatherAppAuthentication(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AppID = 1
+	plugin.InstallationID = 1
+	privateKey, err := os.ReadFile(createTestPrivateKey(t))
+	require.NoError(t, err)
+	plugin.PrivateKey = string(privateKey)
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Empty(t, a.Errors)
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": githubtest.Repo}, "size_kb", 1024))
+	require.True(t, a.HasField("github_info", "traffic_days_available"))
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, int32(1), atomic.LoadInt32(&testServerHandler.InstallationTokens))
+	jwt := plugin.appJWT.jwt
+	require.NotEmpty(t, jwt)
+	plugin.installationToken.expiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, int32(2), atomic.LoadInt32(&testServerHandler.InstallationTokens))
+	require.Equal(t, jwt, plugin.appJWT.jwt)
+	plugin.appJWT.expiresAt = time.Now().Add(30 * time.Second)
+	plugin.installationToken.expiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, int32(3), atomic.LoadInt32(&testServerHandler.InstallationTokens))
+	require.True(t, plugin.appJWT.expiresAt.After(time.Now().Add(appJWTRefresh)))
+}
+
+func createTestPrivateKey(t *testing.T) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKeyPath := filepath.Join(t.TempDir(), "private-key.pem")
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	require.NoError(t, os.WriteFile(privateKeyPath, privateKeyPEM, 0600))
+	return privateKeyPath
+}
+
+func TestGatherRepoEvents(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.RepoEvents = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_repo_event"))
+
+	plugin.repoStates["repo_owner/repo_name"].Visibility = "private"
+	plugin.repoStates["repo_owner/repo_name"].Owner = "old_owner"
+	plugin.repoStates["repo_owner/repo_name"].DefaultBranch = "master"
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_repo_event", map[string]string{"github_repo": "repo_owner/repo_name", "event": "visibility_changed"}, "current", "public"))
+	require.True(t, a.HasPoint("github_repo_event", map[string]string{"github_repo": "repo_owner/repo_name", "event": "owner_changed"}, "previous", "old_owner"))
+	require.True(t, a.HasPoint("github_repo_event", map[string]string{"github_repo": "repo_owner/repo_name", "event": "default_branch_changed"}, "current", "main"))
+}
+
+func TestGatherLFSUsage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.LFSUsage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_lfs_usage"))
+	require.True(t, a.HasPoint("github_lfs_usage", map[string]string{"github_org": "org_name"}, "bandwidth_gb", 5.0))
+	require.True(t, a.HasPoint("github_lfs_usage", map[string]string{"github_org": "org_name"}, "bandwidth_used_percent", 50.0))
+}
+
+func TestGatherIssueTriage(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.IssueTriage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_issue_triage"))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_issue_triage", tags, "issues", 2))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "triaged_issues", 1))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "avg_time_to_label_seconds", 120.0))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "avg_time_to_assign_seconds", 60.0))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "avg_time_to_triage_seconds", 60.0))
+	require.Equal(t, int32(1), atomic.LoadInt32(&testServerHandler.IssueEventLists))
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "triaged_issues", 1))
+	require.True(t, a.HasPoint("github_issue_triage", tags, "avg_time_to_label_seconds", 120.0))
+	require.Equal(t, int32(1), atomic.LoadInt32(&testServerHandler.IssueEventLists))
+}
+
+func TestGatherMaintenanceBranches(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.MaintenanceBranches = []string{"release-1.1", "release-0.9"}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_branch_release"))
+	require.True(t, a.HasPoint("github_branch_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_branch": "release-1.1"}, "latest_release", "v1.1.0"))
+	require.True(t, a.HasPoint("github_branch_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_branch": "release-1.1"}, "commits_since_release", 3))
+	require.True(t, a.HasPoint("github_branch_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_branch": "release-0.9"}, "has_release", false))
+	require.True(t, plugin.excludedReleases["repo_owner/repo_name@release-1.1#v1.2.0"])
+	require.False(t, plugin.excludedReleases["repo_owner/repo_name@release-1.1#v1.1.0"])
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_branch_release", map[string]string{"github_repo": "repo_owner/repo_name", "github_branch": "release-1.1"}, "latest_release", "v1.1.0"))
+}
+
+func TestGatherPathActivity(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ActivityPaths = []string{"services/api", "docs/"}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_path_activity"))
+	require.True(t, a.HasPoint("github_path_activity", map[string]string{"github_repo": "repo_owner/repo_name", "path": "services/api"}, "commits", 2))
+	require.True(t, a.HasPoint("github_path_activity", map[string]string{"github_repo": "repo_owner/repo_name", "path": "services/api"}, "pull_requests", 1))
+	require.True(t, a.HasPoint("github_path_activity", map[string]string{"github_repo": "repo_owner/repo_name", "path": "docs/"}, "pull_requests", 0))
+	requests := atomic.LoadInt32(&testServerHandler.RateLimitUsed)
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_path_activity", map[string]string{"github_repo": "repo_owner/repo_name", "path": "services/api"}, "pull_requests", 1))
+	require.Less(t, atomic.LoadInt32(&testServerHandler.RateLimitUsed)-requests, requests)
+}
+
+func TestGatherIssueForms(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.IssueForms = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_issue_forms", tags, "issues", 2))
+	require.True(t, a.HasPoint("github_issue_forms", tags, "form_issues", 1))
+	require.True(t, a.HasPoint("github_issue_forms", tags, "blank_issues", 1))
+	require.True(t, a.HasPoint("github_issue_forms", tags, "form_fraction", 0.5))
+}
+
+func TestGatherClassicProjects(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.ClassicProjects = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_classic_projects", tags, "projects", 3))
+	require.True(t, a.HasPoint("github_classic_projects", tags, "open_projects", 2))
+	require.True(t, a.HasPoint("github_classic_projects", tags, "closed_projects", 1))
+	require.True(t, a.HasPoint("github_classic_projects", map[string]string{"github_org": "org_name"}, "projects", 0))
+}
+
+func TestGatherOrgRepos(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"org_name/*", "repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	infoMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_info" {
+			infoMetrics++
+			require.Equal(t, "repo_owner/repo_name", metric.Tags()["github_repo"])
+		}
+	}
+	require.Equal(t, 1, infoMetrics)
+}
+
+func TestGatherRepoChurn(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"org_name/*"}
+	plugin.RepoChurn = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_repo_churn"))
+	plugin.discoveredRepos["org:org_name"] = &discoveredRepos{
+		repos:        []string{"repo_owner/archived_repo", "repo_owner/deleted_repo"},
+		discoveredAt: time.Now().Add(-25 * time.Hour),
+	}
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_org": "org_name"}
+	require.True(t, a.HasPoint("github_repo_churn", tags, "repos", 1))
+	require.True(t, a.HasPoint("github_repo_churn", tags, "created_repos", 1))
+	require.True(t, a.HasPoint("github_repo_churn", tags, "removed_repos", 2))
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_repo_churn"))
+}
+
+func TestGatherLanguageTrends(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{githubtest.Org}
+	plugin.LanguageTrends = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	goTags := map[string]string{"github_org": githubtest.Org, "language": "Go"}
+	require.True(t, a.HasPoint("github_org_languages", goTags, "bytes", 3000))
+	require.True(t, a.HasPoint("github_org_languages", goTags, "share_percent", 75.0))
+	require.False(t, a.HasField("github_org_languages", "bytes_delta"))
+	// not gathered again before the discovery interval has elapsed
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_org_languages"))
+	plugin.orgLanguages[githubtest.Org] = map[string]int{"Go": 2000, "Java": 500}
+	delete(plugin.orgLanguagesAt, githubtest.Org)
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_org_languages", goTags, "bytes_delta", 1000))
+	pythonTags := map[string]string{"github_org": githubtest.Org, "language": "Python"}
+	require.True(t, a.HasPoint("github_org_languages", pythonTags, "bytes_delta", 1000))
+	javaTags := map[string]string{"github_org": githubtest.Org, "language": "Java"}
+	require.True(t, a.HasPoint("github_org_languages", javaTags, "bytes", 0))
+	require.True(t, a.HasPoint("github_org_languages", javaTags, "bytes_delta", -500))
+}
+
+func TestResolveRepoPatterns(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"org_name/*_repo", "repo_owner/other_repo"}
+	plugin.ReposExclude = []string{"*/archived_*", "repo_owner/OTHER_*"}
+	plugin.IncludeForks = true
+	plugin.IncludeArchived = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+	client, err := plugin.getClient(context.Background())
+	require.NoError(t, err)
+
+	repos, err := plugin.resolveRepos(context.Background(), client)
+	require.NoError(t, err)
+	require.Equal(t, []string{"repo_owner/forked_repo"}, repos)
+	plugin.Repos = []string{"org_*/*"}
+	_, err = plugin.resolveRepos(context.Background(), client)
+	require.Error(t, err)
+}
+
+func TestGatherAnonymous(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, RateLimit: 3}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "lib_owner/lib_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Anonymous = true
+	plugin.MaxConcurrentRepos = 2
+	plugin.ReleaseStats = true
+	plugin.Referrers = true
+	plugin.CopilotUsage = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "total_download_count", 26))
+	require.True(t, a.HasMeasurement("github_release"))
+	require.False(t, a.HasMeasurement("github_referrers"))
+	require.False(t, a.HasMeasurement("github_copilot_usage"))
+	for _, metric := range a.GetTelegrafMetrics() {
+		require.NotEqual(t, "lib_owner/lib_name", metric.Tags()["github_repo"])
+	}
+	require.EqualValues(t, 2, testServerHandler.RateLimitUsed)
+
+	// the skipped repo is gathered first during the next gather
+	atomic.StoreInt32(&testServerHandler.RateLimitUsed, 0)
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "lib_owner/lib_name"}, "total_download_count", 0))
+	for _, metric := range a.GetTelegrafMetrics() {
+		require.NotEqual(t, "repo_owner/repo_name", metric.Tags()["github_repo"])
+	}
+
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherAnonymousPacing(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true, RateLimit: 12}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "lib_owner/lib_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Anonymous = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.EqualValues(t, 4, testServerHandler.RateLimitUsed)
+
+	// a budget of 12 requests for the 6 gathers until the reset allows for a single repo per gather
+	plugin.GatherInterval = "10m"
+	atomic.StoreInt32(&testServerHandler.RateLimitUsed, 0)
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.EqualValues(t, 2, testServerHandler.RateLimitUsed)
+}
+
+func TestGatherConcurrentRepos(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name", "lib_owner/lib_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.MaxConcurrentRepos = 2
+	plugin.ReleaseDigests = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	infoMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_info" {
+			infoMetrics++
+		}
+	}
+	require.Equal(t, 2, infoMetrics)
+}
+
+func TestGatherCacheDir(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	for restart := 0; restart < 2; restart++ {
+		plugin := NewGitHub()
+		plugin.Repos = []string{"repo_owner/repo_name"}
+		plugin.APIBaseURL = testServer.URL
+		plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+		plugin.CacheDir = cacheDir
+		plugin.Log = createDummyLogger()
+		plugin.Debug = testServerHandler.Debug
+
+		var a testutil.Accumulator
+
+		require.NoError(t, a.GatherError(plugin.Gather))
+		require.True(t, a.HasPoint("github_info", tags, "size_kb", 1024))
+		require.EqualValues(t, restart, testServerHandler.NotModified)
+	}
+}
+
+func TestGatherReposFile(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	reposFile := filepath.Join(t.TempDir(), "github_repos.txt")
+	require.NoError(t, os.WriteFile(reposFile, []byte("# monitored repos\n\nrepo_owner/repo_name # main repo\ninvalid_repo\nrepo_owner/[abc\n"), 0644))
+	plugin := NewGitHub()
+	plugin.ReposFile = reposFile
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	require.NoError(t, os.WriteFile(reposFile, []byte(""), 0644))
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_info"))
+	require.NoError(t, os.Remove(reposFile))
+	require.Error(t, a.GatherError(plugin.Gather))
+}
+
+func TestGatherTopicRepos(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Topics = []string{"telegraf-plugin"}
+	plugin.TopicsOrg = "repo_owner"
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	require.Len(t, plugin.discoveredRepos["topic:telegraf-plugin"].repos, 1)
 }
 
-func TestDescription(t *testing.T) {
+func TestGatherUserRepos(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
 	plugin := NewGitHub()
-	description := plugin.Description()
-	require.NotNil(t, description)
+	plugin.Users = []string{"user_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	discoveredAt := plugin.discoveredRepos["user:user_name"].discoveredAt
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Equal(t, discoveredAt, plugin.discoveredRepos["user:user_name"].discoveredAt)
+	plugin.discoveredRepos["user:user_name"].discoveredAt = discoveredAt.Add(-25 * time.Hour)
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, plugin.discoveredRepos["user:user_name"].discoveredAt.After(discoveredAt))
 }
 
-func TestGather1(t *testing.T) {
-	testServerHandler := &testServerHandler{Debug: true}
+func TestGatherFailedDiscovery(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
 	testServer := httptest.NewServer(testServerHandler)
 	defer testServer.Close()
 	plugin := NewGitHub()
 	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Users = []string{"missing_user"}
 	plugin.APIBaseURL = testServer.URL
-	plugin.AccessToken = "secret_token"
 	plugin.Log = createDummyLogger()
 	plugin.Debug = testServerHandler.Debug
 
 	var a testutil.Accumulator
 
 	require.NoError(t, a.GatherError(plugin.Gather))
-	require.True(t, a.HasMeasurement("github_info"))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	require.Nil(t, plugin.discoveredRepos["user:missing_user"])
+	discoveredAt := time.Now().Add(-25 * time.Hour)
+	plugin.Repos = nil
+	plugin.discoveredRepos["user:missing_user"] = &discoveredRepos{repos: []string{"repo_owner/repo_name"}, discoveredAt: discoveredAt}
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_info", map[string]string{"github_repo": "repo_owner/repo_name"}, "size_kb", 1024))
+	require.Equal(t, discoveredAt, plugin.discoveredRepos["user:missing_user"].discoveredAt)
+}
+
+func TestGatherStatusContexts(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.StatusContexts = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.Empty(t, a.Errors)
+	tags := map[string]string{"github_repo": githubtest.Repo}
+	require.True(t, a.HasPoint("github_status_contexts", tags, "commits", 2))
+	require.True(t, a.HasPoint("github_status_contexts", tags, "contexts", 3))
+	require.True(t, a.HasPoint("github_status_contexts", tags, "missing_contexts", 1))
+	legacyTags := map[string]string{"github_repo": githubtest.Repo, "context": "ci/legacy"}
+	require.True(t, a.HasPoint("github_status_context", legacyTags, "on_latest_commit", false))
+	require.True(t, a.HasPoint("github_status_context", legacyTags, "state", "failure"))
+	buildTags := map[string]string{"github_repo": githubtest.Repo, "context": "build"}
+	require.True(t, a.HasPoint("github_status_context", buildTags, "commits", 2))
+
+	a.ClearMetrics()
+	plugin.MaxStatusContexts = 1
+	require.NoError(t, a.GatherError(plugin.Gather))
+	contextMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_status_context" {
+			contextMetrics++
+			require.Equal(t, "build", metric.Tags()["context"])
+		}
+	}
+	require.Equal(t, 1, contextMetrics)
+}
+
+func TestGatherForkConversion(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.ForkConversion = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": githubtest.Repo}
+	require.True(t, a.HasPoint("github_fork_conversion", tags, "new_forks", 2))
+	require.True(t, a.HasPoint("github_fork_conversion", tags, "external_pull_requests", 1))
+	require.True(t, a.HasPoint("github_fork_conversion", tags, "fork_conversion_ratio", 0.5))
+}
+
+func TestGatherGraphQLBatch(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.GraphQLBatch = true
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_info" {
+			stars, _ := metric.GetField("stargazers_count")
+			require.EqualValues(t, 4242, stars)
+			forks, _ := metric.GetField("forks_count")
+			require.EqualValues(t, 17, forks)
+		}
+	}
+	tags := map[string]string{"github_repo": githubtest.Repo}
+	require.True(t, a.HasPoint("github_info", tags, "total_download_count", 42))
+	require.True(t, a.HasPoint("github_info", tags, "created_at", int64(1609459200)))
+	require.True(t, a.HasPoint("github_info", tags, "total_views", 614))
+	require.Empty(t, plugin.prefetchedRepos)
+}
+
+func TestGatherGraphQLQueries(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.GraphQLQueries = []*GraphQLQuery{
+		{
+			Measurement: "github_discussions",
+			Query:       `query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) { discussions { totalCount } discussionCategories(first: 10) { nodes { name discussions { totalCount } } } } }`,
+			Fields: map[string]string{
+				"discussions":     "repository.discussions.totalCount",
+				"categories":      "repository.discussionCategories.nodes",
+				"top_discussions": "repository.discussionCategories.nodes.0.discussions.totalCount",
+				"upvote_ratio":    "repository.upvoteRatio",
+				"missing":         "repository.missing.totalCount",
+			},
+			Tags: map[string]string{"top_category": "repository.discussionCategories.nodes.0.name"},
+		},
+	}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": githubtest.Repo, "top_category": "Q&A"}
+	require.True(t, a.HasPoint("github_discussions", tags, "discussions", int64(12)))
+	require.True(t, a.HasPoint("github_discussions", tags, "categories", 2))
+	require.True(t, a.HasPoint("github_discussions", tags, "top_discussions", int64(9)))
+	require.True(t, a.HasPoint("github_discussions", tags, "upvote_ratio", 0.75))
+	require.False(t, a.HasField("github_discussions", "missing"))
+
+	plugin.GraphQLQueries[0].Scope = "enterprise"
+	require.Error(t, plugin.checkConfig())
+}
+
+func TestGatherStargazerLocations(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{githubtest.Repo}
+	plugin.StargazerLocations = true
+	plugin.StargazerLocationKeywords = map[string][]string{"DE": {"germany", "munich"}, "US": {"USA"}}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := func(location string) map[string]string {
+		return map[string]string{"github_repo": githubtest.Repo, "location": location}
+	}
+	require.True(t, a.HasPoint("github_stargazer_locations", tags("DE"), "stargazers", 2))
+	require.True(t, a.HasPoint("github_stargazer_locations", tags("DE"), "share_percent", 40.0))
+	require.True(t, a.HasPoint("github_stargazer_locations", tags("US"), "stargazers", 1))
+	require.True(t, a.HasPoint("github_stargazer_locations", tags("<other>"), "stargazers", 1))
+	require.True(t, a.HasPoint("github_stargazer_locations", tags("<unknown>"), "sampled_stargazers", 5))
+
+	// not due again before the interval has passed
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.False(t, a.HasMeasurement("github_stargazer_locations"))
+}
+
+func TestGatherRulesets(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Rulesets = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "ruleset": "main protection", "target": "branch", "enforcement": "active"}
+	require.True(t, a.HasPoint("github_ruleset", tags, "bypass_actors", 2))
+	require.True(t, a.HasPoint("github_ruleset", tags, "always_bypass_actors", 1))
+	require.True(t, a.HasPoint("github_ruleset", tags, "pull_request_bypass_actors", 1))
+	orgTags := map[string]string{"github_org": "org_name", "ruleset": "release tags", "target": "tag", "enforcement": "evaluate"}
+	require.True(t, a.HasPoint("github_ruleset", orgTags, "bypass_actors", 0))
+}
+
+func TestGatherOrgWebhooks(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Orgs = []string{"org_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.OrgWebhooks = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	orgTags := map[string]string{"github_org": "org_name"}
+	require.True(t, a.HasPoint("github_org_webhooks", orgTags, "hooks", 3))
+	require.True(t, a.HasPoint("github_org_webhooks", orgTags, "inactive_hooks", 1))
+	require.True(t, a.HasPoint("github_org_webhooks", orgTags, "failing_hooks", 1))
+	require.True(t, a.HasPoint("github_org_webhook", map[string]string{"github_org": "org_name", "hook_id": "1"}, "last_delivery_success", true))
+	require.True(t, a.HasPoint("github_org_webhook", map[string]string{"github_org": "org_name", "hook_id": "2"}, "has_deliveries", false))
+	require.True(t, a.HasPoint("github_org_webhook", map[string]string{"github_org": "org_name", "hook_id": "3"}, "last_delivery_status_code", 503))
+}
+
+func TestGatherSubmodules(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Submodules = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_submodule"))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "submodule": "vendor/lib"}
+	require.True(t, a.HasPoint("github_submodule", tags, "upstream", "lib_owner/lib_name"))
+	require.True(t, a.HasPoint("github_submodule", tags, "commits_behind", 5))
+	submoduleMetrics := 0
+	for _, metric := range a.GetTelegrafMetrics() {
+		if metric.Name() == "github_submodule" {
+			submoduleMetrics++
+		}
+	}
+	require.Equal(t, 1, submoduleMetrics)
+}
+
+func TestSubmoduleUpstream(t *testing.T) {
+	hosts := []string{"github.com", "github.example.com"}
+	for _, url := range []string{"https://github.com/lib_owner/lib_name.git", "git@github.com:lib_owner/lib_name.git", "https://github.com/lib_owner/lib_name"} {
+		owner, name, ok := submoduleUpstream(url, "repo_owner", hosts)
+		require.True(t, ok)
+		require.Equal(t, "lib_owner", owner)
+		require.Equal(t, "lib_name", name)
+	}
+	owner, name, ok := submoduleUpstream("https://github.example.com/lib_owner/lib_name.git", "repo_owner", hosts)
+	require.True(t, ok)
+	require.Equal(t, "lib_owner", owner)
+	require.Equal(t, "lib_name", name)
+	owner, name, ok = submoduleUpstream("../lib_name.git", "repo_owner", hosts)
+	require.True(t, ok)
+	require.Equal(t, "repo_owner", owner)
+	require.Equal(t, "lib_name", name)
+	_, _, ok = submoduleUpstream("file:///tmp/lib", "repo_owner", hosts)
+	require.False(t, ok)
+	_, _, ok = submoduleUpstream("https://gitlab.com/lib_owner/lib_name.git", "repo_owner", hosts)
+	require.False(t, ok)
+}
+
+func TestGatherDependencyPullRequests(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.DependencyPullRequests = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_dependency_pull_requests"))
+	require.True(t, a.HasPoint("github_dependency_pull_requests", map[string]string{"github_repo": "repo_owner/repo_name"}, "open_pull_requests", 2))
+	maxAge, ok := a.Int64Field("github_dependency_pull_requests", "max_age_seconds")
+	require.True(t, ok)
+	require.Greater(t, maxAge, int64(24*60*60))
+}
+
+func TestGatherPullRequestBranches(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.PullRequestBranches = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_branch": "main"}
+	require.True(t, a.HasPoint("github_pull_request_branch", tags, "open_pull_requests", 2))
+	require.True(t, a.HasPoint("github_pull_request_branch", tags, "default_branch", true))
+	tags["github_branch"] = "release-1.1"
+	require.True(t, a.HasPoint("github_pull_request_branch", tags, "open_pull_requests", 1))
+	require.True(t, a.HasPoint("github_pull_request_branch", tags, "default_branch", false))
+
+	plugin.MaxPullRequestBranches = 1
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags["github_branch"] = "<other>"
+	require.True(t, a.HasPoint("github_pull_request_branch", tags, "open_pull_requests", 1))
+}
+
+func TestGatherMergeConflicts(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.MergeConflicts = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_merge_conflicts", tags, "open_pull_requests", 3))
+	require.True(t, a.HasPoint("github_merge_conflicts", tags, "conflicting_pull_requests", 1))
+	require.True(t, a.HasPoint("github_merge_conflicts", tags, "unknown_pull_requests", 1))
+}
+
+func TestGatherCodeowners(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Codeowners = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_codeowners", tags, "codeowners", 3))
+	require.True(t, a.HasPoint("github_codeowners", tags, "unavailable_codeowners", 1))
+	require.True(t, a.HasPoint("github_codeowners", tags, "pull_requests_with_unavailable_reviewers", 1))
+	requests := atomic.LoadInt32(&testServerHandler.RateLimitUsed)
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasPoint("github_codeowners", tags, "unavailable_codeowners", 1))
+	// the availability of the 3 code owners is remembered for the discovery interval
+	require.Equal(t, requests-3, atomic.LoadInt32(&testServerHandler.RateLimitUsed)-requests)
+}
+
+func TestGatherOpenPullRequestsOnce(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.DependencyPullRequests = true
+	plugin.PullRequestBranches = true
+	plugin.Codeowners = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_dependency_pull_requests"))
+	require.True(t, a.HasMeasurement("github_pull_request_branch"))
+	require.True(t, a.HasMeasurement("github_codeowners"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&testServerHandler.PullRequestLists))
+}
+
+func TestGatherWorkflowConcurrency(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.WorkflowConcurrency = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_workflow": "CI"}
+	require.True(t, a.HasPoint("github_workflow_concurrency", tags, "runs", 3))
+	require.True(t, a.HasPoint("github_workflow_concurrency", tags, "cancelled_runs", 2))
+	require.True(t, a.HasPoint("github_workflow_concurrency", tags, "concurrency_cancelled_runs", 1))
+	tags["github_workflow"] = "Release"
+	require.True(t, a.HasPoint("github_workflow_concurrency", tags, "cancelled_runs", 0))
+}
+
+func TestGatherWorkflowReruns(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.WorkflowReruns = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "github_workflow": "Release"}
+	require.True(t, a.HasPoint("github_workflow_reruns", tags, "rerun_runs", 1))
+	require.True(t, a.HasPoint("github_workflow_reruns", tags, "rerun_ratio", 1.0))
+	tags["github_workflow"] = "CI"
+	require.True(t, a.HasPoint("github_workflow_reruns", tags, "runs", 3))
+	require.True(t, a.HasPoint("github_workflow_reruns", tags, "rerun_ratio", 0.0))
+	// the workflow runs are only listed once for both workflow run stats
+	requests := atomic.LoadInt32(&testServerHandler.RateLimitUsed)
+	plugin.WorkflowConcurrency = true
+	a.ClearMetrics()
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_workflow_concurrency"))
+	require.Equal(t, 2*requests, atomic.LoadInt32(&testServerHandler.RateLimitUsed))
+}
+
+func TestGatherActivity(t *testing.T) {
+	testServerHandler := &githubtest.Handler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.Repos = []string{"repo_owner/repo_name"}
+	plugin.APIBaseURL = testServer.URL
+	plugin.Activity = true
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+
+	var a testutil.Accumulator
+
+	require.NoError(t, a.GatherError(plugin.Gather))
+	require.True(t, a.HasMeasurement("github_activity"))
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	require.True(t, a.HasPoint("github_activity", tags, "issues_human", 2))
+	require.True(t, a.HasPoint("github_activity", tags, "pull_requests_human", 1))
+	require.True(t, a.HasPoint("github_activity", tags, "comments_human", 1))
+	require.True(t, a.HasPoint("github_activity", tags, "comments_bot", 2))
 }
 
 func createDummyLogger() *dummyLogger {
@@ -90,184 +2766,3 @@ func (l *dummyLogger) Infof(format string, args ...interface{}) {
 func (l *dummyLogger) Info(args ...interface{}) {
 	log.Print(args...)
 }
-
-type testServerHandler struct {
-	Debug bool
-}
-
-func (tsh *testServerHandler) ServeHTTP(out http.ResponseWriter, request *http.Request) {
-	requestURL := request.URL.String()
-	if tsh.Debug {
-		log.Printf("test: request URL: %s", requestURL)
-	}
-	if requestURL == "/api/v3/repos/repo_owner/repo_name" {
-		tsh.serveRepositoryInfo(out, request)
-	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/releases" {
-		tsh.serveRepositoryReleases(out, request)
-	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/views?per=day" {
-		tsh.serveRepositoryTrafficViews(out, request)
-	}
-}
-
-const testResourceLight = `
-{
-	"stargazers_count": 1,
-	"forks_count": 2,
-	"subscribers_count": 3
-}
-`
-
-func (tsh *testServerHandler) serveRepositoryInfo(out http.ResponseWriter, request *http.Request) {
-	tsh.writeJSON(out, testResourceLight)
-}
-
-const testRepositoryReleases = `
-[
-  {
-    "assets": [
-      {
-        "download_count": 1
-      },
-      {
-        "download_count": 1
-      },
-      {
-        "download_count": 1
-      },
-      {
-        "download_count": 2
-      },
-      {
-        "download_count": 1
-      },
-      {
-        "download_count": 2
-      }
-    ]
-  },
-  {
-    "assets": [
-      {
-        "download_count": 2
-      },
-      {
-        "download_count": 4
-      },
-      {
-        "download_count": 2
-      },
-      {
-        "download_count": 3
-      },
-      {
-        "download_count": 3
-      },
-      {
-        "download_count": 4
-      }
-    ]
-  },
-  {
-    "assets": [
-
-    ]
-  }
-]
-`
-
-func (tsh *testServerHandler) serveRepositoryReleases(out http.ResponseWriter, request *http.Request) {
-	tsh.writeJSON(out, testRepositoryReleases)
-}
-
-const testRepositoryTrafficViews = `
-{
-	"count": 14850,
-	"uniques": 3782,
-	"views": [
-	  {
-		"timestamp": "2022-10-10T00:00:00Z",
-		"count": 440,
-		"uniques": 143
-	  },
-	  {
-		"timestamp": "2022-10-11T00:00:00Z",
-		"count": 1308,
-		"uniques": 414
-	  },
-	  {
-		"timestamp": "2022-10-12T00:00:00Z",
-		"count": 1486,
-		"uniques": 452
-	  },
-	  {
-		"timestamp": "2022-10-13T00:00:00Z",
-		"count": 1170,
-		"uniques": 401
-	  },
-	  {
-		"timestamp": "2022-10-14T00:00:00Z",
-		"count": 868,
-		"uniques": 266
-	  },
-	  {
-		"timestamp": "2022-10-15T00:00:00Z",
-		"count": 495,
-		"uniques": 157
-	  },
-	  {
-		"timestamp": "2022-10-16T00:00:00Z",
-		"count": 524,
-		"uniques": 175
-	  },
-	  {
-		"timestamp": "2022-10-17T00:00:00Z",
-		"count": 1263,
-		"uniques": 412
-	  },
-	  {
-		"timestamp": "2022-10-18T00:00:00Z",
-		"count": 1402,
-		"uniques": 417
-	  },
-	  {
-		"timestamp": "2022-10-19T00:00:00Z",
-		"count": 1394,
-		"uniques": 424
-	  },
-	  {
-		"timestamp": "2022-10-20T00:00:00Z",
-		"count": 1492,
-		"uniques": 448
-	  },
-	  {
-		"timestamp": "2022-10-21T00:00:00Z",
-		"count": 1153,
-		"uniques": 332
-	  },
-	  {
-		"timestamp": "2022-10-22T00:00:00Z",
-		"count": 566,
-		"uniques": 168
-	  },
-	  {
-		"timestamp": "2022-10-23T00:00:00Z",
-		"count": 675,
-		"uniques": 184
-	  },
-	  {
-		"timestamp": "2022-10-24T00:00:00Z",
-		"count": 614,
-		"uniques": 237
-	  }
-	]
-  }
-`
-
-func (tsh *testServerHandler) serveRepositoryTrafficViews(out http.ResponseWriter, request *http.Request) {
-	tsh.writeJSON(out, testRepositoryTrafficViews)
-}
-
-func (tsh *testServerHandler) writeJSON(out http.ResponseWriter, json string) {
-	out.Header().Add("Content-Type", "application/json")
-	_, _ = out.Write([]byte(json))
-}
@@ -0,0 +1,1966 @@
+// githubtest.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+// Package githubtest provides a test server handler serving canned GitHub API responses for all endpoints used by the
+// github input plugin. It is meant for integration tests of custom builds or execd setups embedding the plugin.
+//
+// The handler serves the repo "repo_owner/repo_name" and the org "org_name" (as well as the deleted repo
+// "repo_owner/deleted_repo"). The GitHub API layout (api/v3, api/graphql),
+// the Gitea API layout (api/v1), the status page API (api/v2) and the Homebrew API (api/homebrew) are all served below
+// the server's root URL, which is therefore suitable as the plugin's api_base_url. Requests for unknown endpoints are
+// answered with an empty response.
+package githubtest
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Repo is the name of the repo served by the handler.
+const Repo = "repo_owner/repo_name"
+
+// Org is the name of the org served by the handler.
+const Org = "org_name"
+
+// Handler serves the canned API responses. The zero value is ready to use.
+type Handler struct {
+	// Debug enables logging of the requested URLs.
+	Debug bool
+	// Incident causes all requests to fail with status 502 (Bad Gateway).
+	Incident bool
+	// TransientFailures is the number of upcoming requests to fail with status 503 (Service Unavailable) and a Retry-After
+	// header requesting an immediate retry. It must be accessed atomically while the handler is in use.
+	TransientFailures int32
+	// RetryAfter overrides the Retry-After header (in seconds) sent along with the transient failures.
+	RetryAfter string
+	// PagedReleases splits the repo's releases across two pages.
+	PagedReleases bool
+	// RateLimit enables the X-RateLimit-Remaining header, counting down from the given limit.
+	RateLimit int
+	// RateLimitUsed counts the served requests and is reported via the X-RateLimit-Used header. It must be accessed
+	// atomically while the handler is in use.
+	RateLimitUsed int32
+	// NotModified counts the conditional requests answered with status 304 (Not Modified). It must be accessed
+	// atomically while the handler is in use.
+	NotModified int32
+	// InstallationTokens counts the created app installation tokens. It must be accessed atomically while the handler is
+	// in use.
+	InstallationTokens int32
+	// IssueEventLists counts the served issue event listings. It must be accessed atomically while the handler is in use.
+	IssueEventLists int32
+	// PullRequestLists counts the served open pull request listings. It must be accessed atomically while the handler is
+	// in use.
+	PullRequestLists int32
+
+	recentOnce sync.Once
+	recent     time.Time
+}
+
+// InstallationToken is the app installation token issued by the handler.
+const InstallationToken = "installation_token"
+
+// ServeHTTP implements http.Handler.
+func (tsh *Handler) ServeHTTP(out http.ResponseWriter, request *http.Request) {
+	requestURL := request.URL.String()
+	if tsh.Debug {
+		log.Printf("test: request URL: %s", requestURL)
+	}
+	if tsh.Incident {
+		out.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	if atomic.LoadInt32(&tsh.TransientFailures) > 0 && atomic.AddInt32(&tsh.TransientFailures, -1) >= 0 {
+		retryAfter := tsh.RetryAfter
+		if retryAfter == "" {
+			retryAfter = "0"
+		}
+		out.Header().Set("Retry-After", retryAfter)
+		out.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if authorization := request.Header.Get("Authorization"); strings.HasPrefix(authorization, "token ") && authorization != "token "+InstallationToken {
+		out.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if request.Header.Get("Authorization") == "Bearer exhausted_token" {
+		out.Header().Add("Content-Type", "application/json")
+		out.Header().Set("X-RateLimit-Limit", "5000")
+		out.Header().Set("X-RateLimit-Remaining", "0")
+		out.Header().Set("X-RateLimit-Used", "5000")
+		out.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		out.WriteHeader(http.StatusForbidden)
+		_, _ = out.Write([]byte(`{"message": "API rate limit exceeded"}`))
+		return
+	}
+	if request.Header.Get("Authorization") == "Bearer revoked_token" {
+		out.Header().Add("Content-Type", "application/json")
+		out.WriteHeader(http.StatusUnauthorized)
+		_, _ = out.Write([]byte(`{"message": "Bad credentials"}`))
+		return
+	}
+	out.Header().Set("X-RateLimit-Resource", "core")
+	rateLimitUsed := int(atomic.AddInt32(&tsh.RateLimitUsed, 1))
+	out.Header().Set("X-RateLimit-Used", strconv.Itoa(rateLimitUsed))
+	if tsh.RateLimit > 0 {
+		out.Header().Set("X-RateLimit-Remaining", strconv.Itoa(tsh.RateLimit-rateLimitUsed))
+		out.Header().Set("X-RateLimit-Limit", strconv.Itoa(tsh.RateLimit))
+		out.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	}
+	if requestURL == "/api/v3/repos/repo_owner/deleted_repo" {
+		out.Header().Add("Content-Type", "application/json")
+		out.WriteHeader(http.StatusNotFound)
+		_, _ = out.Write([]byte(`{"message": "Not Found"}`))
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name" {
+		tsh.serveRepositoryInfo(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/releases?per_page=100" {
+		if tsh.PagedReleases {
+			out.Header().Set("Link", `<`+request.URL.Path+`?page=2&per_page=100>; rel="next", <`+request.URL.Path+`?page=2&per_page=100>; rel="last"`)
+		}
+		tsh.serveRepositoryReleases(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/releases?page=2&per_page=100" {
+		tsh.serveRepositoryReleasesPage2(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/views?per=day" {
+		tsh.serveRepositoryTrafficViews(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/popular/referrers" {
+		tsh.serveRepositoryTrafficReferrers(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/views?per=week" {
+		tsh.serveRepositoryTrafficViewsWeekly(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/clones?per=week" {
+		tsh.serveRepositoryTrafficClonesWeekly(out, request)
+	} else if requestURL == "/api/v1/repos/repo_owner/repo_name" {
+		tsh.serveGiteaRepositoryInfo(out, request)
+	} else if requestURL == "/api/v1/repos/repo_owner/repo_name/releases?per_page=100" {
+		tsh.serveRepositoryReleases(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/projects?per_page=100&state=all" {
+		tsh.serveRepositoryProjects(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/rulesets?includes_parents=false&per_page=100&page=1" {
+		tsh.writeJSON(out, repoRulesets)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/rulesets/42" {
+		tsh.writeJSON(out, repoRuleset42)
+	} else if requestURL == "/api/v3/orgs/org_name/rulesets?per_page=100&page=1" {
+		tsh.writeJSON(out, orgRulesets)
+	} else if requestURL == "/api/v3/orgs/org_name/rulesets/7" {
+		tsh.writeJSON(out, orgRuleset7)
+	} else if requestURL == "/api/v3/orgs/org_name/projects?per_page=100&state=all" {
+		out.Header().Add("Content-Type", "application/json")
+		out.WriteHeader(http.StatusGone)
+		_, _ = out.Write([]byte(`{"message": "Projects (classic) has been deprecated in favor of the new Projects experience."}`))
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/commits?per_page=5&sha=main" {
+		tsh.writeJSON(out, repositoryRecentCommits)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/commits/aaa111/status?per_page=100" {
+		tsh.writeJSON(out, `{"state": "success", "statuses": [{"context": "ci/jenkins", "state": "success"}]}`)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/commits/bbb222/status?per_page=100" {
+		tsh.writeJSON(out, `{"state": "failure", "statuses": [{"context": "ci/jenkins", "state": "success"}, {"context": "ci/legacy", "state": "failure"}]}`)
+	} else if strings.HasPrefix(requestURL, "/api/v3/repos/repo_owner/repo_name/commits/") && strings.HasSuffix(requestURL, "/check-runs?per_page=100") {
+		tsh.writeJSON(out, `{"total_count": 1, "check_runs": [{"name": "build", "status": "completed", "conclusion": "success"}]}`)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/forks?per_page=100&sort=newest" {
+		tsh.writeJSONTemplate(out, repositoryForks)
+	} else if requestURL == "/api/v3/user" {
+		out.Header().Set("X-OAuth-Scopes", "public_repo")
+		tsh.writeJSON(out, `{"login": "octocat"}`)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/languages" {
+		tsh.writeJSON(out, repositoryLanguages)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/tags?per_page=100" {
+		tsh.writeJSON(out, repositoryTags)
+	} else if requestURL == "/api/v3/search/repositories?per_page=100&q=topic%3Atelegraf-plugin+org%3Arepo_owner" {
+		tsh.writeJSON(out, `{"total_count": 3, "incomplete_results": false, "items": `+orgRepos+`}`)
+	} else if requestURL == "/api/v3/users/missing_user/repos?per_page=100&type=owner" {
+		out.Header().Add("Content-Type", "application/json")
+		out.WriteHeader(http.StatusNotFound)
+		_, _ = out.Write([]byte(`{"message": "Not Found"}`))
+	} else if requestURL == "/api/v3/users/user_name/repos?per_page=100&type=owner" {
+		tsh.writeJSON(out, orgRepos)
+	} else if requestURL == "/api/v3/orgs/org_name/repos?per_page=100&type=all" {
+		tsh.writeJSON(out, orgRepos)
+	} else if requestURL == "/api/v3/orgs/org_name/hooks?per_page=100" {
+		tsh.writeJSON(out, orgHooks)
+	} else if requestURL == "/api/v3/orgs/org_name/hooks/1/deliveries?per_page=1" {
+		tsh.writeJSONTemplate(out, orgHook1Deliveries)
+	} else if requestURL == "/api/v3/orgs/org_name/hooks/2/deliveries?per_page=1" {
+		tsh.writeJSON(out, "[]")
+	} else if requestURL == "/api/v3/orgs/org_name/hooks/3/deliveries?per_page=1" {
+		tsh.writeJSONTemplate(out, orgHook3Deliveries)
+	} else if requestURL == "/api/v3/rate_limit" {
+		tsh.writeJSON(out, rateLimit)
+	} else if requestURL == "/api/homebrew/formula/repo_name.json" {
+		tsh.writeJSON(out, homebrewFormula)
+	} else if requestURL == "/api/v2/components.json" {
+		tsh.serveStatusPageComponents(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/popular/paths" {
+		tsh.serveRepositoryTrafficPaths(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/traffic/clones?per=day" {
+		tsh.serveRepositoryTrafficClones(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/actions/workflows?per_page=100" {
+		tsh.serveRepositoryWorkflows(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/.github/workflows/nightly.yml" {
+		tsh.serveRepositoryWorkflowContent(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/.github/workflows/build.yml" {
+		tsh.serveRepositoryWorkflowContentNoSchedule(out, request)
+	} else if strings.HasPrefix(requestURL, "/api/v3/repos/repo_owner/repo_name/contents/dynamic/") {
+		out.WriteHeader(http.StatusNotFound)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/.github/workflows/removed.yml" {
+		out.WriteHeader(http.StatusNotFound)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/actions/workflows/1/runs?event=schedule&per_page=1" {
+		tsh.serveRepositoryWorkflowRuns(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/environments?per_page=100" {
+		tsh.serveRepositoryEnvironments(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/deployments?per_page=100" {
+		tsh.serveRepositoryDeployments(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/deployments/1/statuses?per_page=100" {
+		tsh.serveRepositoryDeploymentStatuses1(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/deployments/2/statuses?per_page=100" {
+		tsh.serveRepositoryDeploymentStatuses2(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/rulesets?includes_parents=true&per_page=100&page=1" {
+		tsh.writeJSON(out, repoTagRulesets)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/rulesets/43" {
+		tsh.writeJSON(out, repoRuleset43)
+	} else if strings.HasPrefix(requestURL, "/api/v3/organizations/org_name/settings/billing/usage?") {
+		tsh.serveOrgBillingUsage(out, request)
+	} else if requestURL == "/api/v3/orgs/repo_owner/teams/team_slug/repos?per_page=100" {
+		tsh.serveTeamRepos(out, request)
+	} else if requestURL == "/api/v3/orgs/org_name/copilot/metrics" {
+		tsh.serveOrgCopilotMetrics(out, request)
+	} else if strings.HasPrefix(requestURL, "/api/v3/orgs/org_name/audit-log?") {
+		tsh.serveOrgAuditLog(out, request)
+	} else if requestURL == "/api/graphql" {
+		tsh.serveGraphQL(out, request)
+	} else if requestURL == "/api/v3/orgs/org_name/credential-authorizations?per_page=100&page=1" {
+		tsh.serveOrgCredentialAuthorizations(out, request)
+	} else if requestURL == "/api/v3/orgs/org_name/personal-access-token-requests?per_page=100&page=1" {
+		tsh.serveOrgPATRequests(out, request)
+	} else if requestURL == "/api/v3/app/installations/1" {
+		tsh.serveAppInstallation(out, request)
+	} else if requestURL == "/api/v3/app/installations/1/access_tokens" && request.Method == http.MethodPost {
+		tsh.serveAppInstallationToken(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/issues?direction=desc&per_page=100&sort=created&state=all" {
+		tsh.serveRepositoryIssues(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/issues/3/events?per_page=100" {
+		tsh.serveRepositoryIssueEvents(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/compare/v1.1.0...release-1.1" {
+		tsh.serveRepositoryCompare(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/compare/v1.2.0...release-1.1" {
+		tsh.writeJSON(out, repositoryCompareDiverged)
+	} else if strings.HasPrefix(requestURL, "/api/v3/repos/repo_owner/repo_name/commits?path=services%2Fapi&") {
+		tsh.serveRepositoryPathCommits(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/pulls/4/files?per_page=100" {
+		tsh.serveRepositoryPullRequestFiles(out, request)
+	} else if strings.HasPrefix(requestURL, "/api/v3/repos/repo_owner/repo_name/actions/runs?created=%3E%3D") {
+		tsh.writeJSONTemplate(out, repositoryWindowWorkflowRuns)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/.github/CODEOWNERS" {
+		out.WriteHeader(http.StatusNotFound)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/CODEOWNERS" {
+		tsh.writeJSON(out, repositoryCodeowners)
+	} else if requestURL == "/api/v3/orgs/repo_owner/members/octocat" {
+		out.WriteHeader(http.StatusNoContent)
+	} else if requestURL == "/api/v3/orgs/repo_owner/members/former-member" {
+		out.WriteHeader(http.StatusNotFound)
+	} else if requestURL == "/api/v3/orgs/org_name/teams/platform" {
+		tsh.writeJSON(out, `{"id": 1, "slug": "platform"}`)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/.gitmodules" {
+		tsh.serveRepositoryGitmodules(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/contents/vendor/lib" {
+		tsh.serveRepositorySubmodule(out, request)
+	} else if requestURL == "/api/v3/repos/lib_owner/lib_name" {
+		tsh.serveSubmoduleRepositoryInfo(out, request)
+	} else if requestURL == "/api/v3/repos/lib_owner/lib_name/compare/a1b2c3d4...main" {
+		tsh.serveSubmoduleCompare(out, request)
+	} else if requestURL == "/api/v3/repos/repo_owner/repo_name/pulls?per_page=100&state=open" {
+		tsh.serveRepositoryOpenPullRequests(out, request)
+	} else if strings.HasPrefix(requestURL, "/api/v3/repos/repo_owner/repo_name/issues/comments?per_page=100&since=") {
+		tsh.serveRepositoryIssueComments(out, request)
+	}
+}
+
+const resourceLight = `
+{
+	"full_name": "repo_owner/repo_name",
+	"owner": {
+		"login": "repo_owner",
+		"type": "Organization"
+	},
+	"visibility": "public",
+	"default_branch": "main",
+	"created_at": "2021-01-01T00:00:00Z",
+	"permissions": {
+		"admin": false,
+		"push": true,
+		"pull": true
+	},
+	"size": 1024,
+	"stargazers_count": 1,
+	"forks_count": 2,
+	"subscribers_count": 3
+}
+`
+
+func (tsh *Handler) serveRepositoryInfo(out http.ResponseWriter, request *http.Request) {
+	const etag = `"repo_name"`
+	if request.Header.Get("If-None-Match") == etag {
+		atomic.AddInt32(&tsh.NotModified, 1)
+		out.WriteHeader(http.StatusNotModified)
+		return
+	}
+	out.Header().Set("ETag", etag)
+	tsh.writeJSON(out, resourceLight)
+}
+
+// RepositoryReleases contains the releases served for the repo.
+const RepositoryReleases = `
+[
+  {
+    "id": 3,
+    "tag_name": "v1.2.0",
+    "published_at": "2022-10-20T00:00:00Z",
+    "body": "## What's Changed\n\n* Fix startup crash",
+    "assets": [
+      {
+        "name": "plugin-linux-amd64.tar.gz",
+        "size": 1048576,
+        "download_count": 1
+      },
+      {
+        "name": "plugin-linux-amd64.tar.gz.sig",
+        "download_count": 1
+      },
+      {
+        "download_count": 1
+      },
+      {
+        "content_type": "application/zip",
+        "download_count": 2
+      },
+      {
+        "download_count": 1
+      },
+      {
+        "download_count": 2
+      }
+    ]
+  },
+  {
+    "id": 2,
+    "tag_name": "v1.1.0",
+    "target_commitish": "release-1.1",
+    "published_at": "2022-09-20T00:00:00Z",
+    "assets": [
+      {
+        "name": "plugin.spdx.json",
+        "download_count": 2
+      },
+      {
+        "download_count": 4
+      },
+      {
+        "download_count": 2
+      },
+      {
+        "download_count": 3
+      },
+      {
+        "download_count": 3
+      },
+      {
+        "download_count": 4
+      }
+    ]
+  },
+  {
+    "id": 1,
+    "tag_name": "v1.0.0",
+    "published_at": "2022-08-20T00:00:00Z",
+    "assets": [
+
+    ]
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryReleases(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, RepositoryReleases)
+}
+
+const repositoryTrafficViews = `
+{
+	"count": 14850,
+	"uniques": 3782,
+	"views": [
+	  {
+		"timestamp": "2022-10-10T00:00:00Z",
+		"count": 440,
+		"uniques": 143
+	  },
+	  {
+		"timestamp": "2022-10-11T00:00:00Z",
+		"count": 1308,
+		"uniques": 414
+	  },
+	  {
+		"timestamp": "2022-10-12T00:00:00Z",
+		"count": 1486,
+		"uniques": 452
+	  },
+	  {
+		"timestamp": "2022-10-13T00:00:00Z",
+		"count": 1170,
+		"uniques": 401
+	  },
+	  {
+		"timestamp": "2022-10-14T00:00:00Z",
+		"count": 868,
+		"uniques": 266
+	  },
+	  {
+		"timestamp": "2022-10-15T00:00:00Z",
+		"count": 495,
+		"uniques": 157
+	  },
+	  {
+		"timestamp": "2022-10-16T00:00:00Z",
+		"count": 524,
+		"uniques": 175
+	  },
+	  {
+		"timestamp": "2022-10-17T00:00:00Z",
+		"count": 1263,
+		"uniques": 412
+	  },
+	  {
+		"timestamp": "2022-10-18T00:00:00Z",
+		"count": 1402,
+		"uniques": 417
+	  },
+	  {
+		"timestamp": "2022-10-19T00:00:00Z",
+		"count": 1394,
+		"uniques": 424
+	  },
+	  {
+		"timestamp": "2022-10-20T00:00:00Z",
+		"count": 1492,
+		"uniques": 448
+	  },
+	  {
+		"timestamp": "2022-10-21T00:00:00Z",
+		"count": 1153,
+		"uniques": 332
+	  },
+	  {
+		"timestamp": "2022-10-22T00:00:00Z",
+		"count": 566,
+		"uniques": 168
+	  },
+	  {
+		"timestamp": "2022-10-23T00:00:00Z",
+		"count": 675,
+		"uniques": 184
+	  },
+	  {
+		"timestamp": "2022-10-24T00:00:00Z",
+		"count": 614,
+		"uniques": 237
+	  }
+	]
+  }
+`
+
+func (tsh *Handler) serveRepositoryTrafficViews(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficViews)
+}
+
+const repositoryWorkflows = `
+{
+  "total_count": 4,
+  "workflows": [
+    {
+      "id": 1,
+      "name": "Nightly",
+      "path": ".github/workflows/nightly.yml",
+      "state": "disabled_inactivity",
+      "created_at": "2022-10-01T00:00:00Z"
+    },
+    {
+      "id": 2,
+      "name": "Build",
+      "path": ".github/workflows/build.yml",
+      "state": "active",
+      "created_at": "2022-10-01T00:00:00Z"
+    },
+    {
+      "id": 3,
+      "name": "pages-build-deployment",
+      "path": "dynamic/pages/pages-build-deployment",
+      "state": "active",
+      "created_at": "2022-10-01T00:00:00Z"
+    },
+    {
+      "id": 4,
+      "name": "Removed",
+      "path": ".github/workflows/removed.yml",
+      "state": "active",
+      "created_at": "2022-10-01T00:00:00Z"
+    }
+  ]
+}
+`
+
+func (tsh *Handler) serveRepositoryWorkflows(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryWorkflows)
+}
+
+// base64 encoded workflow defining the schedules '0 2 * * *' and '30 4 * * MON-FRI'
+const repositoryWorkflowContent = `
+{
+  "type": "file",
+  "encoding": "base64",
+  "path": ".github/workflows/nightly.yml",
+  "content": "b246CiAgc2NoZWR1bGU6CiAgICAtIGNyb246ICcwIDIgKiAqIConCiAgICAtIGNyb246ICIzMCA0ICogKiBNT04tRlJJIiAjIHdvcmtkYXlzCg=="
+}
+`
+
+func (tsh *Handler) serveRepositoryWorkflowContent(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryWorkflowContent)
+}
+
+// base64 encoded workflow without schedules
+const repositoryWorkflowContentNoSchedule = `
+{
+  "type": "file",
+  "encoding": "base64",
+  "path": ".github/workflows/build.yml",
+  "content": "b246CiAgcHVzaDoK"
+}
+`
+
+func (tsh *Handler) serveRepositoryWorkflowContentNoSchedule(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryWorkflowContentNoSchedule)
+}
+
+const repositoryWorkflowRuns = `
+{
+  "total_count": 1,
+  "workflow_runs": [
+    {
+      "id": 100,
+      "event": "schedule",
+      "created_at": "2022-10-24T02:00:00Z"
+    }
+  ]
+}
+`
+
+func (tsh *Handler) serveRepositoryWorkflowRuns(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryWorkflowRuns)
+}
+
+const repositoryEnvironments = `
+{
+  "total_count": 1,
+  "environments": [
+    {
+      "id": 1,
+      "name": "production",
+      "protection_rules": [
+        {
+          "id": 1,
+          "type": "wait_timer",
+          "wait_timer": 30
+        },
+        {
+          "id": 2,
+          "type": "required_reviewers",
+          "reviewers": [
+            {
+              "type": "User",
+              "reviewer": {
+                "id": 1,
+                "login": "octocat"
+              }
+            },
+            {
+              "type": "Team",
+              "reviewer": {
+                "id": 1,
+                "name": "Justice League"
+              }
+            }
+          ]
+        }
+      ],
+      "deployment_branch_policy": {
+        "protected_branches": true,
+        "custom_branch_policies": false
+      }
+    }
+  ]
+}
+`
+
+func (tsh *Handler) serveRepositoryEnvironments(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryEnvironments)
+}
+
+const repositoryDeployments = `
+[
+  {
+    "id": 2,
+    "environment": "production",
+    "created_at": "{{.Recent}}"
+  },
+  {
+    "id": 1,
+    "environment": "production",
+    "created_at": "{{.Recent}}"
+  },
+  {
+    "id": 0,
+    "environment": "production",
+    "created_at": "2022-10-01T00:00:00Z"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryDeployments(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSONTemplate(out, repositoryDeployments)
+}
+
+const repositoryDeploymentStatuses1 = `
+[
+  {
+    "id": 12,
+    "state": "success",
+    "created_at": "{{.RecentPlus60s}}"
+  },
+  {
+    "id": 11,
+    "state": "in_progress",
+    "created_at": "{{.Recent}}"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryDeploymentStatuses1(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSONTemplate(out, repositoryDeploymentStatuses1)
+}
+
+const repositoryDeploymentStatuses2 = `
+[
+  {
+    "id": 21,
+    "state": "failure",
+    "created_at": "{{.RecentPlus120s}}"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryDeploymentStatuses2(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSONTemplate(out, repositoryDeploymentStatuses2)
+}
+
+const orgBillingUsage = `
+{
+  "usageItems": [
+    {
+      "date": "2022-10-01",
+      "product": "Actions",
+      "sku": "Actions Linux",
+      "quantity": 100,
+      "unitType": "Minutes",
+      "netAmount": 0.8,
+      "organizationName": "org_name",
+      "repositoryName": "org_name/repo_name"
+    },
+    {
+      "date": "2022-10-02",
+      "product": "Actions",
+      "sku": "Actions Linux",
+      "quantity": 50,
+      "unitType": "Minutes",
+      "netAmount": 0.4,
+      "organizationName": "org_name",
+      "repositoryName": "org_name/repo_name"
+    },
+    {
+      "date": "2022-10-02",
+      "product": "Actions",
+      "sku": "Actions macOS 3-core",
+      "quantity": 10,
+      "unitType": "Minutes",
+      "netAmount": 0.8,
+      "organizationName": "org_name",
+      "repositoryName": "org_name/repo_name"
+    },
+    {
+      "date": "2022-10-03",
+      "product": "Actions",
+      "sku": "Actions Linux",
+      "quantity": 40,
+      "unitType": "Minutes",
+      "netAmount": 0.32,
+      "organizationName": "org_name",
+      "repositoryName": "other_repo"
+    },
+    {
+      "date": "2022-10-02",
+      "product": "git_lfs",
+      "sku": "Git LFS bandwidth",
+      "quantity": 5,
+      "unitType": "Gigabytes",
+      "netAmount": 0.0,
+      "organizationName": "org_name"
+    },
+    {
+      "date": "2022-10-02",
+      "product": "Packages",
+      "sku": "Packages storage",
+      "quantity": 1,
+      "unitType": "GigabyteHours",
+      "netAmount": 0.1,
+      "organizationName": "org_name",
+      "repositoryName": "org_name/repo_name"
+    }
+  ]
+}
+`
+
+func (tsh *Handler) serveOrgBillingUsage(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, orgBillingUsage)
+}
+
+const teamRepos = `
+[
+  {
+    "id": 1,
+    "name": "repo_name",
+    "full_name": "repo_owner/repo_name"
+  }
+]
+`
+
+func (tsh *Handler) serveTeamRepos(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, teamRepos)
+}
+
+const orgCopilotMetrics = `
+[
+  {
+    "date": "2022-10-15",
+    "total_active_users": 10,
+    "total_engaged_users": 8,
+    "copilot_ide_code_completions": {
+      "total_engaged_users": 8,
+      "languages": [
+        {
+          "name": "go",
+          "total_engaged_users": 6
+        },
+        {
+          "name": "python",
+          "total_engaged_users": 4
+        }
+      ],
+      "editors": [
+        {
+          "name": "vscode",
+          "total_engaged_users": 6,
+          "models": [
+            {
+              "name": "default",
+              "is_custom_model": false,
+              "total_engaged_users": 6,
+              "languages": [
+                {
+                  "name": "go",
+                  "total_engaged_users": 6,
+                  "total_code_suggestions": 600,
+                  "total_code_acceptances": 500,
+                  "total_code_lines_suggested": 1000,
+                  "total_code_lines_accepted": 700
+                },
+                {
+                  "name": "python",
+                  "total_engaged_users": 1,
+                  "total_code_suggestions": 100,
+                  "total_code_acceptances": 50,
+                  "total_code_lines_suggested": 200,
+                  "total_code_lines_accepted": 100
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "name": "jetbrains",
+          "total_engaged_users": 3,
+          "models": [
+            {
+              "name": "default",
+              "is_custom_model": false,
+              "total_engaged_users": 3,
+              "languages": [
+                {
+                  "name": "python",
+                  "total_engaged_users": 3,
+                  "total_code_suggestions": 300,
+                  "total_code_acceptances": 250,
+                  "total_code_lines_suggested": 600,
+                  "total_code_lines_accepted": 400
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "date": "2022-10-16",
+    "total_active_users": 0,
+    "total_engaged_users": 0
+  }
+]
+`
+
+func (tsh *Handler) serveOrgCopilotMetrics(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, orgCopilotMetrics)
+}
+
+const orgAuditLog = `
+[
+  {
+    "action": "repo.create",
+    "actor": "octocat"
+  },
+  {
+    "action": "org.update_member",
+    "actor": "octocat"
+  },
+  {
+    "action": "repo.create",
+    "actor": "octocat"
+  }
+]
+`
+
+func (tsh *Handler) serveOrgAuditLog(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, orgAuditLog)
+}
+
+const graphQLIPAllowList = `
+{
+  "data": {
+    "organization": {
+      "ipAllowListEnabledSetting": "ENABLED",
+      "ipAllowListEntries": {
+        "totalCount": 2,
+        "nodes": [
+          {
+            "isActive": true
+          },
+          {
+            "isActive": false
+          }
+        ]
+      }
+    }
+  }
+}
+`
+
+func (tsh *Handler) serveGraphQL(out http.ResponseWriter, request *http.Request) {
+	query, _ := io.ReadAll(request.Body)
+	if strings.Contains(string(query), "discussionCategories") {
+		tsh.writeJSON(out, graphQLDiscussions)
+	} else if strings.Contains(string(query), "...repoFields") {
+		tsh.writeJSON(out, graphQLRepositoryBatch)
+	} else if strings.Contains(string(query), "ipAllowListEnabledSetting") {
+		tsh.writeJSON(out, graphQLIPAllowList)
+	} else if strings.Contains(string(query), "mergeable") && strings.Contains(string(query), `"cursor":"cursor1"`) {
+		tsh.writeJSON(out, graphQLPullRequestMergeable2)
+	} else if strings.Contains(string(query), "mergeable") {
+		tsh.writeJSON(out, graphQLPullRequestMergeable1)
+	} else if strings.Contains(string(query), "stargazers") {
+		tsh.writeJSON(out, graphQLStargazerLocations)
+	}
+}
+
+const graphQLDiscussions = `
+{
+  "data": {
+    "repository": {
+      "discussions": {
+        "totalCount": 12
+      },
+      "discussionCategories": {
+        "nodes": [
+          {"name": "Q&A", "discussions": {"totalCount": 9}},
+          {"name": "Ideas", "discussions": {"totalCount": 3}}
+        ]
+      },
+      "upvoteRatio": 0.75
+    }
+  }
+}
+`
+
+const graphQLRepositoryBatch = `
+{
+  "data": {
+    "r0": {
+      "nameWithOwner": "repo_owner/repo_name",
+      "owner": {
+        "__typename": "User",
+        "login": "repo_owner"
+      },
+      "isPrivate": false,
+      "visibility": "PUBLIC",
+      "defaultBranchRef": {
+        "name": "main"
+      },
+      "createdAt": "2021-01-01T00:00:00Z",
+      "diskUsage": 108,
+      "stargazerCount": 4242,
+      "forkCount": 17,
+      "watchers": {
+        "totalCount": 5
+      },
+      "viewerPermission": "ADMIN",
+      "releases": {
+        "pageInfo": {
+          "hasNextPage": false
+        },
+        "nodes": [
+          {
+            "databaseId": 1,
+            "tagName": "v1.0.0",
+            "name": "v1.0.0",
+            "description": "Initial release",
+            "isDraft": false,
+            "isPrerelease": false,
+            "createdAt": "2021-01-01T00:00:00Z",
+            "publishedAt": "2021-01-01T00:00:00Z",
+            "releaseAssets": {
+              "pageInfo": {
+                "hasNextPage": false
+              },
+              "nodes": [
+                {
+                  "name": "release_linux_amd64.tar.gz",
+                  "contentType": "application/gzip",
+                  "size": 1024,
+                  "downloadCount": 30,
+                  "createdAt": "2021-01-01T00:00:00Z",
+                  "updatedAt": "2021-01-01T00:00:00Z"
+                },
+                {
+                  "name": "release_windows_amd64.zip",
+                  "contentType": "application/zip",
+                  "size": 2048,
+                  "downloadCount": 12,
+                  "createdAt": "2021-01-01T00:00:00Z",
+                  "updatedAt": "2021-01-01T00:00:00Z"
+                }
+              ]
+            }
+          }
+        ]
+      }
+    },
+    "r1": null
+  },
+  "errors": [
+    {
+      "type": "NOT_FOUND",
+      "path": ["r1"],
+      "message": "Could not resolve to a Repository with the name 'repo_owner/unknown_repo'."
+    }
+  ]
+}
+`
+
+const graphQLStargazerLocations = `
+{
+  "data": {
+    "repository": {
+      "stargazers": {
+        "pageInfo": {
+          "hasNextPage": false,
+          "endCursor": "cursor1"
+        },
+        "nodes": [
+          {"location": "Berlin, Germany"},
+          {"location": "Munich"},
+          {"location": "New York, USA"},
+          {"location": "Tokyo"},
+          {"location": null}
+        ]
+      }
+    }
+  }
+}
+`
+
+const graphQLPullRequestMergeable1 = `
+{
+  "data": {
+    "repository": {
+      "pullRequests": {
+        "pageInfo": {
+          "hasNextPage": true,
+          "endCursor": "cursor1"
+        },
+        "nodes": [
+          {
+            "mergeable": "MERGEABLE"
+          },
+          {
+            "mergeable": "CONFLICTING"
+          }
+        ]
+      }
+    }
+  }
+}
+`
+
+const graphQLPullRequestMergeable2 = `
+{
+  "data": {
+    "repository": {
+      "pullRequests": {
+        "pageInfo": {
+          "hasNextPage": false,
+          "endCursor": "cursor2"
+        },
+        "nodes": [
+          {
+            "mergeable": "UNKNOWN"
+          }
+        ]
+      }
+    }
+  }
+}
+`
+
+const orgCredentialAuthorizations = `
+[
+  {
+    "login": "octocat",
+    "credential_id": 1,
+    "credential_type": "personal access token"
+  },
+  {
+    "login": "octocat",
+    "credential_id": 2,
+    "credential_type": "personal access token"
+  },
+  {
+    "login": "hubot",
+    "credential_id": 3,
+    "credential_type": "personal access token"
+  },
+  {
+    "login": "hubot",
+    "credential_id": 4,
+    "credential_type": "SSH key"
+  }
+]
+`
+
+func (tsh *Handler) serveOrgCredentialAuthorizations(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, orgCredentialAuthorizations)
+}
+
+const orgPATRequests = `
+[
+  {
+    "id": 1,
+    "reason": "Access to the plugin repo",
+    "created_at": "2022-10-20T00:00:00Z"
+  },
+  {
+    "id": 2,
+    "reason": "Release automation",
+    "created_at": "2022-10-22T00:00:00Z"
+  }
+]
+`
+
+func (tsh *Handler) serveOrgPATRequests(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, orgPATRequests)
+}
+
+const appInstallation = `
+{
+  "id": 1,
+  "account": {
+    "login": "org_name"
+  },
+  "permissions": {
+    "contents": "read",
+    "metadata": "read",
+    "issues": "write"
+  }
+}
+`
+
+func (tsh *Handler) serveAppInstallation(out http.ResponseWriter, request *http.Request) {
+	if !strings.HasPrefix(request.Header.Get("Authorization"), "Bearer ") {
+		out.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	tsh.writeJSON(out, appInstallation)
+}
+
+func (tsh *Handler) serveAppInstallationToken(out http.ResponseWriter, request *http.Request) {
+	if !strings.HasPrefix(request.Header.Get("Authorization"), "Bearer ") {
+		out.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	atomic.AddInt32(&tsh.InstallationTokens, 1)
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	tsh.writeJSON(out, `{"token": "`+InstallationToken+`", "expires_at": "`+expiresAt+`"}`)
+}
+
+const repositoryIssues = `
+[
+  {
+    "number": 4,
+    "title": "Pull request",
+    "state": "closed",
+    "created_at": "{{.Recent}}",
+    "closed_at": "{{.RecentPlus120s}}",
+    "author_association": "FIRST_TIME_CONTRIBUTOR",
+    "user": {
+      "login": "octocat"
+    },
+    "pull_request": {
+      "url": "https://api.github.com/repos/repo_owner/repo_name/pulls/4"
+    }
+  },
+  {
+    "number": 3,
+    "title": "Triaged issue",
+    "body": "### Version\n\nv1.2.0\n\n### What happened?\n\nCrash on startup",
+    "state": "closed",
+    "created_at": "{{.Recent}}",
+    "closed_at": "{{.RecentPlus120s}}",
+    "user": {
+      "login": "octocat"
+    }
+  },
+  {
+    "number": 2,
+    "title": "Untriaged issue",
+    "body": "It doesn't work",
+    "created_at": "{{.Recent}}",
+    "user": {
+      "login": "octocat"
+    }
+  },
+  {
+    "number": 1,
+    "title": "Old issue",
+    "created_at": "2022-10-01T00:00:00Z",
+    "user": {
+      "login": "octocat"
+    }
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryIssues(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSONTemplate(out, repositoryIssues)
+}
+
+const repositoryIssueEvents = `
+[
+  {
+    "id": 1,
+    "event": "assigned",
+    "created_at": "{{.RecentPlus60s}}"
+  },
+  {
+    "id": 2,
+    "event": "labeled",
+    "created_at": "{{.RecentPlus120s}}"
+  },
+  {
+    "id": 3,
+    "event": "labeled",
+    "created_at": "{{.RecentPlus120s}}"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryIssueEvents(out http.ResponseWriter, request *http.Request) {
+	atomic.AddInt32(&tsh.IssueEventLists, 1)
+	tsh.writeJSONTemplate(out, repositoryIssueEvents)
+}
+
+const repositoryCompare = `
+{
+  "status": "ahead",
+  "ahead_by": 3,
+  "behind_by": 0,
+  "total_commits": 3
+}
+`
+
+const repositoryCompareDiverged = `
+{
+  "status": "diverged",
+  "ahead_by": 3,
+  "behind_by": 5,
+  "total_commits": 3
+}
+`
+
+func (tsh *Handler) serveRepositoryCompare(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryCompare)
+}
+
+const repositoryPathCommits = `
+[
+  {
+    "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+  },
+  {
+    "sha": "7638417db6d59f3c431d3e1f261cc637155684cd"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryPathCommits(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryPathCommits)
+}
+
+const repositoryPullRequestFiles = `
+[
+  {
+    "filename": "services/api/main.go",
+    "status": "modified"
+  },
+  {
+    "filename": "services/api/main_test.go",
+    "status": "modified"
+  },
+  {
+    "filename": "docs.md",
+    "status": "added"
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryPullRequestFiles(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryPullRequestFiles)
+}
+
+const repositoryWindowWorkflowRuns = `
+{
+  "total_count": 4,
+  "workflow_runs": [
+    {
+      "id": 4,
+      "name": "Release",
+      "workflow_id": 2,
+      "head_branch": "main",
+      "event": "push",
+      "status": "completed",
+      "conclusion": "success",
+      "run_attempt": 2,
+      "created_at": "{{.RecentPlus60s}}",
+      "updated_at": "{{.RecentPlus120s}}"
+    },
+    {
+      "id": 3,
+      "name": "CI",
+      "workflow_id": 1,
+      "head_branch": "main",
+      "event": "push",
+      "status": "completed",
+      "conclusion": "success",
+      "run_attempt": 1,
+      "created_at": "{{.RecentPlus60s}}",
+      "updated_at": "{{.RecentPlus120s}}"
+    },
+    {
+      "id": 2,
+      "name": "CI",
+      "workflow_id": 1,
+      "head_branch": "feature",
+      "event": "push",
+      "status": "completed",
+      "conclusion": "cancelled",
+      "run_attempt": 1,
+      "created_at": "{{.Recent}}",
+      "updated_at": "{{.Recent}}"
+    },
+    {
+      "id": 1,
+      "name": "CI",
+      "workflow_id": 1,
+      "head_branch": "main",
+      "event": "push",
+      "status": "completed",
+      "conclusion": "cancelled",
+      "run_attempt": 1,
+      "created_at": "{{.Recent}}",
+      "updated_at": "{{.RecentPlus60s}}"
+    }
+  ]
+}
+`
+
+// base64 encoded CODEOWNERS referencing the users octocat and former-member as well as the team org_name/platform
+const repositoryCodeowners = `
+{
+  "type": "file",
+  "encoding": "base64",
+  "path": "CODEOWNERS",
+  "content": "IyBkZWZhdWx0IG93bmVycwoqICAgICAgIEBvY3RvY2F0IEBvcmdfbmFtZS9wbGF0Zm9ybQovZG9jcy8gIEBmb3JtZXItbWVtYmVyIGRvY3NAZXhhbXBsZS5jb20K"
+}
+`
+
+// base64 encoded .gitmodules defining the submodules vendor/lib (hosted on GitHub) and vendor/other (hosted elsewhere)
+const repositoryGitmodules = `
+{
+  "type": "file",
+  "encoding": "base64",
+  "path": ".gitmodules",
+  "content": "W3N1Ym1vZHVsZSAidmVuZG9yL2xpYiJdCglwYXRoID0gdmVuZG9yL2xpYgoJdXJsID0gaHR0cHM6Ly9naXRodWIuY29tL2xpYl9vd25lci9saWJfbmFtZS5naXQKW3N1Ym1vZHVsZSAidmVuZG9yL290aGVyIl0KCXBhdGggPSB2ZW5kb3Ivb3RoZXIKCXVybCA9IGh0dHBzOi8vZ2l0bGFiLmNvbS9saWJfb3duZXIvb3RoZXJfbGliLmdpdAo="
+}
+`
+
+func (tsh *Handler) serveRepositoryGitmodules(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryGitmodules)
+}
+
+const repositorySubmodule = `
+{
+  "type": "submodule",
+  "path": "vendor/lib",
+  "sha": "a1b2c3d4",
+  "submodule_git_url": "https://github.com/lib_owner/lib_name.git"
+}
+`
+
+func (tsh *Handler) serveRepositorySubmodule(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositorySubmodule)
+}
+
+const submoduleRepositoryInfo = `
+{
+  "full_name": "lib_owner/lib_name",
+  "default_branch": "main"
+}
+`
+
+func (tsh *Handler) serveSubmoduleRepositoryInfo(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, submoduleRepositoryInfo)
+}
+
+const submoduleCompare = `
+{
+  "status": "ahead",
+  "ahead_by": 5,
+  "behind_by": 0,
+  "total_commits": 5
+}
+`
+
+func (tsh *Handler) serveSubmoduleCompare(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, submoduleCompare)
+}
+
+const repositoryOpenPullRequests = `
+[
+  {
+    "number": 4,
+    "state": "open",
+    "created_at": "{{.Recent}}",
+    "user": {
+      "login": "dependabot[bot]",
+      "type": "Bot"
+    },
+    "base": {
+      "ref": "main"
+    }
+  },
+  {
+    "number": 3,
+    "state": "open",
+    "created_at": "2022-10-01T00:00:00Z",
+    "user": {
+      "login": "renovate[bot]",
+      "type": "Bot"
+    },
+    "base": {
+      "ref": "main"
+    }
+  },
+  {
+    "number": 2,
+    "state": "open",
+    "created_at": "2022-10-01T00:00:00Z",
+    "user": {
+      "login": "octocat",
+      "type": "User"
+    },
+    "requested_reviewers": [
+      {
+        "login": "former-member"
+      }
+    ],
+    "base": {
+      "ref": "release-1.1"
+    }
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryOpenPullRequests(out http.ResponseWriter, request *http.Request) {
+	atomic.AddInt32(&tsh.PullRequestLists, 1)
+	tsh.writeJSONTemplate(out, repositoryOpenPullRequests)
+}
+
+const repositoryIssueComments = `
+[
+  {
+    "id": 1,
+    "body": "Looks good",
+    "created_at": "{{.Recent}}",
+    "user": {
+      "login": "octocat",
+      "type": "User"
+    }
+  },
+  {
+    "id": 2,
+    "body": "Coverage report",
+    "created_at": "{{.Recent}}",
+    "user": {
+      "login": "codecov",
+      "type": "Bot"
+    }
+  },
+  {
+    "id": 3,
+    "body": "Rebased",
+    "created_at": "{{.Recent}}",
+    "user": {
+      "login": "dependabot[bot]"
+    }
+  }
+]
+`
+
+func (tsh *Handler) serveRepositoryIssueComments(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSONTemplate(out, repositoryIssueComments)
+}
+
+func (tsh *Handler) writeJSONTemplate(out http.ResponseWriter, jsonTemplate string) {
+	// the recent time is fixed on first use to keep the served timestamps consistent across requests
+	tsh.recentOnce.Do(func() {
+		tsh.recent = time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	})
+	recent := tsh.recent
+	data := map[string]string{
+		"Recent":         recent.Format(time.RFC3339),
+		"RecentPlus60s":  recent.Add(60 * time.Second).Format(time.RFC3339),
+		"RecentPlus120s": recent.Add(120 * time.Second).Format(time.RFC3339),
+	}
+	var json strings.Builder
+	_ = template.Must(template.New("json").Parse(jsonTemplate)).Execute(&json, data)
+	tsh.writeJSON(out, json.String())
+}
+
+const repositoryTrafficClones = `
+{
+	"count": 173,
+	"uniques": 128,
+	"clones": [
+	  {
+		"timestamp": "2022-10-22T00:00:00Z",
+		"count": 2,
+		"uniques": 1
+	  },
+	  {
+		"timestamp": "2022-10-23T00:00:00Z",
+		"count": 145,
+		"uniques": 118
+	  },
+	  {
+		"timestamp": "2022-10-24T00:00:00Z",
+		"count": 26,
+		"uniques": 9
+	  }
+	]
+  }
+`
+
+func (tsh *Handler) serveRepositoryTrafficClones(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficClones)
+}
+
+const repositoryTrafficViewsWeekly = `
+{
+	"count": 14850,
+	"uniques": 3782,
+	"views": [
+	  {
+		"timestamp": "2022-10-10T00:00:00Z",
+		"count": 7763,
+		"uniques": 1844
+	  },
+	  {
+		"timestamp": "2022-10-17T00:00:00Z",
+		"count": 7087,
+		"uniques": 1938
+	  }
+	]
+  }
+`
+
+func (tsh *Handler) serveRepositoryTrafficViewsWeekly(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficViewsWeekly)
+}
+
+const repositoryTrafficClonesWeekly = `
+{
+	"count": 173,
+	"uniques": 128,
+	"clones": [
+	  {
+		"timestamp": "2022-10-17T00:00:00Z",
+		"count": 173,
+		"uniques": 128
+	  }
+	]
+  }
+`
+
+func (tsh *Handler) serveRepositoryTrafficClonesWeekly(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficClonesWeekly)
+}
+
+const repositoryReleasesPage2 = `
+[
+	{
+	  "id": 0,
+	  "tag_name": "v0.9.0",
+	  "name": "v0.9.0",
+	  "prerelease": true,
+	  "published_at": "2022-01-01T00:00:00Z",
+	  "assets": [
+		{
+		  "id": 0,
+		  "name": "binary-0.9.0.tar.gz",
+		  "download_count": 4
+		}
+	  ]
+	}
+]
+`
+
+func (tsh *Handler) serveRepositoryReleasesPage2(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryReleasesPage2)
+}
+
+const repositoryProjects = `
+[
+	{
+	  "id": 1,
+	  "name": "Roadmap",
+	  "state": "open"
+	},
+	{
+	  "id": 2,
+	  "name": "Release 1.0",
+	  "state": "closed"
+	},
+	{
+	  "id": 3,
+	  "name": "Backlog",
+	  "state": "open"
+	}
+]
+`
+
+func (tsh *Handler) serveRepositoryProjects(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryProjects)
+}
+
+const repositoryTags = `
+[
+	{
+	  "name": "v1.2.0"
+	},
+	{
+	  "name": "v1.1.1"
+	},
+	{
+	  "name": "v1.1.0"
+	},
+	{
+	  "name": "v1.0.0"
+	},
+	{
+	  "name": "nightly"
+	}
+]
+`
+
+const orgRepos = `
+[
+	{
+	  "full_name": "repo_owner/repo_name",
+	  "fork": false,
+	  "archived": false
+	},
+	{
+	  "full_name": "repo_owner/forked_repo",
+	  "fork": true,
+	  "archived": false
+	},
+	{
+	  "full_name": "repo_owner/archived_repo",
+	  "fork": false,
+	  "archived": true
+	}
+]
+`
+
+const repositoryRecentCommits = `
+[
+	{
+		"sha": "aaa111"
+	},
+	{
+		"sha": "bbb222"
+	}
+]
+`
+
+const repositoryForks = `
+[
+	{
+		"full_name": "fork_owner1/repo_name",
+		"created_at": "{{.Recent}}"
+	},
+	{
+		"full_name": "fork_owner2/repo_name",
+		"created_at": "{{.RecentPlus60s}}"
+	},
+	{
+		"full_name": "fork_owner3/repo_name",
+		"created_at": "2022-10-01T00:00:00Z"
+	}
+]
+`
+
+const repositoryLanguages = `
+{
+	"Go": 3000,
+	"Python": 1000
+}
+`
+
+const repoRulesets = `
+[
+	{
+	  "id": 42,
+	  "name": "main protection",
+	  "target": "branch",
+	  "enforcement": "active"
+	}
+]
+`
+
+const repoRuleset42 = `
+{
+  "id": 42,
+  "name": "main protection",
+  "target": "branch",
+  "enforcement": "active",
+  "bypass_actors": [
+	{
+	  "actor_id": 5,
+	  "actor_type": "RepositoryRole",
+	  "bypass_mode": "always"
+	},
+	{
+	  "actor_id": 2,
+	  "actor_type": "Integration",
+	  "bypass_mode": "pull_request"
+	}
+  ]
+}
+`
+
+const repoTagRulesets = `
+[
+	{
+	  "id": 42,
+	  "name": "main protection",
+	  "target": "branch",
+	  "enforcement": "active"
+	},
+	{
+	  "id": 43,
+	  "name": "version tags",
+	  "target": "tag",
+	  "enforcement": "active"
+	},
+	{
+	  "id": 7,
+	  "name": "release tags",
+	  "target": "tag",
+	  "enforcement": "evaluate"
+	}
+]
+`
+
+const repoRuleset43 = `
+{
+  "id": 43,
+  "name": "version tags",
+  "target": "tag",
+  "enforcement": "active",
+  "conditions": {
+	"ref_name": {
+	  "include": ["refs/tags/v*", "refs/tags/release-*"],
+	  "exclude": []
+	}
+  },
+  "bypass_actors": []
+}
+`
+
+const orgRulesets = `
+[
+	{
+	  "id": 7,
+	  "name": "release tags",
+	  "target": "tag",
+	  "enforcement": "evaluate"
+	}
+]
+`
+
+const orgRuleset7 = `
+{
+  "id": 7,
+  "name": "release tags",
+  "target": "tag",
+  "enforcement": "evaluate",
+  "bypass_actors": []
+}
+`
+
+const orgHooks = `
+[
+	{
+	  "id": 1,
+	  "name": "web",
+	  "active": true,
+	  "events": ["push", "pull_request"]
+	},
+	{
+	  "id": 2,
+	  "name": "web",
+	  "active": false,
+	  "events": ["*"]
+	},
+	{
+	  "id": 3,
+	  "name": "web",
+	  "active": true,
+	  "events": ["release"]
+	}
+]
+`
+
+const orgHook1Deliveries = `
+[
+	{
+	  "id": 11,
+	  "delivered_at": "{{.Recent}}",
+	  "status": "OK",
+	  "status_code": 200,
+	  "event": "push"
+	}
+]
+`
+
+const orgHook3Deliveries = `
+[
+	{
+	  "id": 31,
+	  "delivered_at": "{{.Recent}}",
+	  "status": "Invalid HTTP Response: 503",
+	  "status_code": 503,
+	  "event": "release"
+	}
+]
+`
+
+const giteaRepositoryInfo = `
+{
+	"id": 1,
+	"owner": {
+	  "id": 1,
+	  "login": "repo_owner"
+	},
+	"name": "repo_name",
+	"full_name": "repo_owner/repo_name",
+	"private": false,
+	"fork": false,
+	"size": 2048,
+	"stars_count": 42,
+	"forks_count": 7,
+	"watchers_count": 5,
+	"open_issues_count": 3,
+	"default_branch": "main"
+}
+`
+
+func (tsh *Handler) serveGiteaRepositoryInfo(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, giteaRepositoryInfo)
+}
+
+const rateLimit = `
+{
+  "resources": {
+    "core": {"limit": 5000, "used": 1, "remaining": 4999, "reset": 4102444800},
+    "search": {"limit": 30, "used": 12, "remaining": 18, "reset": 4102444800},
+    "graphql": {"limit": 5000, "used": 7, "remaining": 4993, "reset": 4102444800},
+    "integration_manifest": {"limit": 5000, "used": 0, "remaining": 5000, "reset": 4102444800}
+  },
+  "rate": {"limit": 5000, "used": 1, "remaining": 4999, "reset": 4102444800}
+}
+`
+
+const homebrewFormula = `
+{
+  "name": "repo_name",
+  "full_name": "repo_name",
+  "analytics": {
+    "install": {
+      "30d": {"repo_name": 120, "repo_name --HEAD": 3},
+      "90d": {"repo_name": 400},
+      "365d": {"repo_name": 1500}
+    },
+    "install_on_request": {
+      "30d": {"repo_name": 100},
+      "90d": {"repo_name": 350},
+      "365d": {"repo_name": 1300}
+    },
+    "build_error": {
+      "30d": {"repo_name": 2}
+    }
+  }
+}
+`
+
+const statusPageComponents = `
+{
+	"page": {
+	  "id": "kctbh9vrtdwd",
+	  "name": "GitHub",
+	  "url": "https://www.githubstatus.com"
+	},
+	"components": [
+	  {
+		"id": "8l4ygp009s5s",
+		"name": "Git Operations",
+		"status": "operational",
+		"group": false
+	  },
+	  {
+		"id": "brv1bkgrwx7q",
+		"name": "API Requests",
+		"status": "operational",
+		"group": false
+	  },
+	  {
+		"id": "br0l2tvcx85d",
+		"name": "Actions",
+		"status": "partial_outage",
+		"group": false
+	  },
+	  {
+		"id": "st3j38cctv9l",
+		"name": "Packages",
+		"status": "degraded_performance",
+		"group": false
+	  },
+	  {
+		"id": "vg70hn9s2tyj",
+		"name": "Pages",
+		"status": "operational",
+		"group": false
+	  }
+	]
+}
+`
+
+func (tsh *Handler) serveStatusPageComponents(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, statusPageComponents)
+}
+
+const repositoryTrafficReferrers = `
+[
+	{
+	  "referrer": "Google",
+	  "count": 4,
+	  "uniques": 3
+	},
+	{
+	  "referrer": "github.com",
+	  "count": 2,
+	  "uniques": 1
+	}
+]
+`
+
+func (tsh *Handler) serveRepositoryTrafficReferrers(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficReferrers)
+}
+
+const repositoryTrafficPaths = `
+[
+	{
+	  "path": "/repo_owner/repo_name",
+	  "title": "repo_owner/repo_name: Sample repository",
+	  "count": 3542,
+	  "uniques": 2225
+	},
+	{
+	  "path": "/repo_owner/repo_name/blob/main/README.md",
+	  "title": "repo_name/README.md at main",
+	  "count": 98,
+	  "uniques": 48
+	}
+]
+`
+
+func (tsh *Handler) serveRepositoryTrafficPaths(out http.ResponseWriter, request *http.Request) {
+	tsh.writeJSON(out, repositoryTrafficPaths)
+}
+
+func (tsh *Handler) writeJSON(out http.ResponseWriter, json string) {
+	out.Header().Add("Content-Type", "application/json")
+	_, _ = out.Write([]byte(json))
+}
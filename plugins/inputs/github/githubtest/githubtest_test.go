@@ -0,0 +1,39 @@
+// githubtest_test.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package githubtest
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	handler := &Handler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client, err := githubApi.NewEnterpriseClient(server.URL, "", nil)
+	require.NoError(t, err)
+	repoOwnerAndName := strings.Split(Repo, "/")
+
+	repoInfo, _, err := client.Repositories.Get(context.Background(), repoOwnerAndName[0], repoOwnerAndName[1])
+	require.NoError(t, err)
+	require.Equal(t, Repo, repoInfo.GetFullName())
+	repoReleases, _, err := client.Repositories.ListReleases(context.Background(), repoOwnerAndName[0], repoOwnerAndName[1], &githubApi.ListOptions{PerPage: 100})
+	require.NoError(t, err)
+	require.NotEmpty(t, repoReleases)
+	require.EqualValues(t, 2, handler.RateLimitUsed)
+
+	handler.Incident = true
+	_, _, err = client.Repositories.Get(context.Background(), repoOwnerAndName[0], repoOwnerAndName[1])
+	require.Error(t, err)
+}
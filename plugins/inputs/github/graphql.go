@@ -0,0 +1,68 @@
+// graphql.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// queryGraphQL runs the given GraphQL query and decodes the response data into the submitted result. The GraphQL
+// endpoint is derived from the REST API base URL (https://api.github.com/graphql or <host>/api/graphql).
+func (plugin *GitHub) queryGraphQL(ctx context.Context, client *githubApi.Client, query string, variables map[string]interface{}, result interface{}) error {
+	messages, err := plugin.queryGraphQLPartial(ctx, client, query, variables, result)
+	if err != nil {
+		return err
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("github: GraphQL query failed: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// queryGraphQLPartial runs the given GraphQL query like queryGraphQL, but also decodes partial response data (e.g. if
+// some of multiple queried repos are not accessible). The error messages reported by GraphQL are returned separately.
+func (plugin *GitHub) queryGraphQLPartial(ctx context.Context, client *githubApi.Client, query string, variables map[string]interface{}, result interface{}) ([]string, error) {
+	request, err := client.NewRequest("POST", "../graphql", &graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+	response := &graphQLResponse{}
+	_, err = client.Do(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0, len(response.Errors))
+	for _, responseError := range response.Errors {
+		messages = append(messages, responseError.Message)
+	}
+	if len(response.Data) == 0 || string(response.Data) == "null" {
+		if len(messages) > 0 {
+			return messages, nil
+		}
+		return nil, errors.New("github: Empty GraphQL response")
+	}
+	return messages, json.Unmarshal(response.Data, result)
+}
@@ -0,0 +1,157 @@
+// graphqlqueries.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const graphQLQueryScopeRepo = "repo"
+const graphQLQueryScopeOrg = "org"
+
+const defaultGraphQLQueryMeasurement = "github_graphql"
+
+// GraphQLQuery is a user-defined GraphQL query together with the mapping of the query result to the emitted point.
+type GraphQLQuery struct {
+	Measurement string            `toml:"measurement"`
+	Scope       string            `toml:"scope"`
+	Query       string            `toml:"query"`
+	Fields      map[string]string `toml:"fields"`
+	Tags        map[string]string `toml:"tags"`
+}
+
+func (plugin *GitHub) checkGraphQLQueries() error {
+	for index, query := range plugin.GraphQLQueries {
+		if query.Measurement == "" {
+			query.Measurement = defaultGraphQLQueryMeasurement
+		}
+		if query.Scope == "" {
+			query.Scope = graphQLQueryScopeRepo
+		}
+		if query.Scope != graphQLQueryScopeRepo && query.Scope != graphQLQueryScopeOrg {
+			return fmt.Errorf("github: Invalid GraphQL query scope '%s'", query.Scope)
+		}
+		if strings.TrimSpace(query.Query) == "" {
+			return fmt.Errorf("github: Empty GraphQL query #%d", index+1)
+		}
+		if len(query.Fields) == 0 {
+			return fmt.Errorf("github: Empty field mapping of GraphQL query #%d", index+1)
+		}
+	}
+	return nil
+}
+
+// processGraphQLQueries runs the user-defined GraphQL queries of the given scope. The repo scope provides the variables
+// $owner, $name and $repo, the org scope the variable $org (only the variables referenced by the query are submitted).
+func (plugin *GitHub) processGraphQLQueries(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, scope string, scopeTags map[string]string, scopeVariables map[string]interface{}) error {
+	for _, query := range plugin.GraphQLQueries {
+		if query.Scope != scope {
+			continue
+		}
+		variables := make(map[string]interface{})
+		for name, value := range scopeVariables {
+			if strings.Contains(query.Query, "$"+name) {
+				variables[name] = value
+			}
+		}
+		var result json.RawMessage
+		err := plugin.queryGraphQL(ctx, client, query.Query, variables, &result)
+		if err != nil {
+			return err
+		}
+		data, err := decodeGraphQLQueryResult(result)
+		if err != nil {
+			return err
+		}
+		tags := make(map[string]string)
+		for name, value := range scopeTags {
+			tags[name] = value
+		}
+		for name, path := range query.Tags {
+			value, found := graphQLQueryValue(data, path)
+			if found && value != nil {
+				tags[name] = fmt.Sprint(value)
+			}
+		}
+		fields := make(map[string]interface{})
+		for name, path := range query.Fields {
+			value, found := graphQLQueryValue(data, path)
+			if !found || value == nil {
+				if plugin.Debug {
+					plugin.Log.Infof("Skipping field %s of measurement %s due to missing value at '%s'", name, query.Measurement, path)
+				}
+				continue
+			}
+			fields[name] = value
+		}
+		if len(fields) > 0 {
+			a.AddCounter(query.Measurement, fields, tags)
+		}
+	}
+	return nil
+}
+
+func decodeGraphQLQueryResult(result json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+	var data interface{}
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// graphQLQueryValue resolves the given dot separated path (numeric path segments indexing lists) within the query result.
+// Numbers are returned as int64 if integral (float64 otherwise), lists and objects are reported by their length.
+func graphQLQueryValue(data interface{}, path string) (interface{}, bool) {
+	value := data
+	for _, segment := range strings.Split(path, ".") {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			child, found := typedValue[segment]
+			if !found {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(typedValue) {
+				return nil, false
+			}
+			value = typedValue[index]
+		default:
+			return nil, false
+		}
+	}
+	switch typedValue := value.(type) {
+	case json.Number:
+		intValue, err := typedValue.Int64()
+		if err == nil {
+			return intValue, true
+		}
+		floatValue, err := typedValue.Float64()
+		if err != nil {
+			return nil, false
+		}
+		return floatValue, true
+	case map[string]interface{}:
+		return len(typedValue), true
+	case []interface{}:
+		return len(typedValue), true
+	}
+	return value, true
+}
@@ -0,0 +1,83 @@
+// homebrew.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+const defaultHomebrewAPIURL = "https://formulae.brew.sh/api"
+
+// homebrewFormula represents the analytics part of a Homebrew formula (the counts per period are keyed by the formula
+// name respectively the formula name plus the install options).
+type homebrewFormula struct {
+	Analytics struct {
+		Install          map[string]map[string]int `json:"install"`
+		InstallOnRequest map[string]map[string]int `json:"install_on_request"`
+		BuildError       map[string]map[string]int `json:"build_error"`
+	} `json:"analytics"`
+}
+
+// homebrewPeriods are the analytics periods reported by the Homebrew API.
+var homebrewPeriods = []string{"30d", "90d", "365d"}
+
+func (plugin *GitHub) processHomebrew(ctx context.Context, a telegraf.Accumulator, repo string, formulaName string) error {
+	formula, err := plugin.getHomebrewFormula(ctx, formulaName)
+	if err != nil {
+		return err
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	tags["formula"] = formulaName
+	fields := make(map[string]interface{})
+	for _, period := range homebrewPeriods {
+		fields["installs_"+period] = sumHomebrewCounts(formula.Analytics.Install[period])
+		fields["installs_on_request_"+period] = sumHomebrewCounts(formula.Analytics.InstallOnRequest[period])
+	}
+	fields["build_errors_30d"] = sumHomebrewCounts(formula.Analytics.BuildError["30d"])
+	a.AddCounter("github_homebrew", fields, tags)
+	return nil
+}
+
+func sumHomebrewCounts(counts map[string]int) int {
+	sum := 0
+	for _, count := range counts {
+		sum += count
+	}
+	return sum
+}
+
+func (plugin *GitHub) getHomebrewFormula(ctx context.Context, formulaName string) (*homebrewFormula, error) {
+	formulaURL := strings.TrimSuffix(plugin.HomebrewAPIURL, "/") + "/formula/" + url.PathEscape(formulaName) + ".json"
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, formulaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := plugin.externalClient
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: Homebrew formula '%s' request failed with status %d", formulaName, response.StatusCode)
+	}
+	formula := &homebrewFormula{}
+	err = json.NewDecoder(response.Body).Decode(formula)
+	if err != nil {
+		return nil, err
+	}
+	return formula, nil
+}
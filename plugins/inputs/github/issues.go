@@ -0,0 +1,242 @@
+// issues.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// listRecentIssues lists all issues (including pull requests) created since the given time.
+func (plugin *GitHub) listRecentIssues(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, since time.Time) ([]*githubApi.Issue, error) {
+	recentIssues := make([]*githubApi.Issue, 0)
+	opts := &githubApi.IssueListByRepoOptions{State: "all", Sort: "created", Direction: "desc", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		issues, response, err := client.Issues.ListByRepo(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.GetCreatedAt().Before(since) {
+				return recentIssues, nil
+			}
+			recentIssues = append(recentIssues, issue)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return recentIssues, nil
+}
+
+// recentIssuesRequired reports whether any of the enabled stats evaluates the issues created within the window (which are
+// listed once per repo and gather for all of them).
+func (plugin *GitHub) recentIssuesRequired() bool {
+	return plugin.IssueTriage || plugin.IssueForms || plugin.Activity || plugin.ForkConversion || len(plugin.ActivityPaths) > 0
+}
+
+// closedIssueTriage holds the first triage events of a closed issue. As the triage events of closed issues rarely
+// change, they are remembered (for as long as the issue is within the window) instead of being listed on every gather.
+type closedIssueTriage struct {
+	closedAt      time.Time
+	firstLabeled  time.Time
+	firstAssigned time.Time
+}
+
+func (plugin *GitHub) processIssueTriage(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, recentIssues []*githubApi.Issue) error {
+	knownTriages := plugin.closedIssueTriages(repo)
+	closedTriages := make(map[int]*closedIssueTriage)
+	issueCount := 0
+	labeled := 0
+	labelSeconds := 0.0
+	assigned := 0
+	assignSeconds := 0.0
+	triaged := 0
+	triageSeconds := 0.0
+	for _, issue := range recentIssues {
+		if issue.IsPullRequest() {
+			continue
+		}
+		issueCount++
+		triage := knownTriages[issue.GetNumber()]
+		// reopened (and closed again) issues are listed again
+		if issue.GetState() != "closed" || triage == nil || !triage.closedAt.Equal(issue.GetClosedAt()) {
+			firstLabeled, firstAssigned, err := plugin.getFirstTriageEvents(ctx, client, repoOwner, repoName, issue.GetNumber())
+			if err != nil {
+				return err
+			}
+			triage = &closedIssueTriage{closedAt: issue.GetClosedAt(), firstLabeled: firstLabeled, firstAssigned: firstAssigned}
+		}
+		if issue.GetState() == "closed" {
+			closedTriages[issue.GetNumber()] = triage
+		}
+		firstLabeled := triage.firstLabeled
+		firstAssigned := triage.firstAssigned
+		createdAt := issue.GetCreatedAt()
+		firstTriaged := time.Time{}
+		if !firstLabeled.IsZero() {
+			labeled++
+			labelSeconds += firstLabeled.Sub(createdAt).Seconds()
+			firstTriaged = firstLabeled
+		}
+		if !firstAssigned.IsZero() {
+			assigned++
+			assignSeconds += firstAssigned.Sub(createdAt).Seconds()
+			if firstTriaged.IsZero() || firstAssigned.Before(firstTriaged) {
+				firstTriaged = firstAssigned
+			}
+		}
+		if !firstTriaged.IsZero() {
+			triaged++
+			triageSeconds += firstTriaged.Sub(createdAt).Seconds()
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["issues"] = issueCount
+	fields["triaged_issues"] = triaged
+	fields["untriaged_issues"] = issueCount - triaged
+	fields["avg_time_to_label_seconds"] = average(labelSeconds, labeled)
+	fields["avg_time_to_assign_seconds"] = average(assignSeconds, assigned)
+	fields["avg_time_to_triage_seconds"] = average(triageSeconds, triaged)
+	a.AddCounter("github_issue_triage", fields, tags)
+	plugin.updateClosedIssueTriages(repo, closedTriages)
+	return nil
+}
+
+func (plugin *GitHub) closedIssueTriages(repo string) map[int]*closedIssueTriage {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	return plugin.issueTriages[repo]
+}
+
+// updateClosedIssueTriages replaces the remembered triage events of the repo's closed issues (thereby dropping the ones
+// of issues no longer within the window).
+func (plugin *GitHub) updateClosedIssueTriages(repo string, closedTriages map[int]*closedIssueTriage) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	plugin.issueTriages[repo] = closedTriages
+}
+
+// processIssueForms counts the recent issues created via issue forms (respectively templates), which are recognized by
+// their body starting with a section heading (issue forms render every form field as "### <label>" section).
+func (plugin *GitHub) processIssueForms(a telegraf.Accumulator, repo string, recentIssues []*githubApi.Issue) {
+	issueCount := 0
+	formIssues := 0
+	for _, issue := range recentIssues {
+		if issue.IsPullRequest() {
+			continue
+		}
+		issueCount++
+		if strings.HasPrefix(strings.TrimSpace(issue.GetBody()), "### ") {
+			formIssues++
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["issues"] = issueCount
+	fields["form_issues"] = formIssues
+	fields["blank_issues"] = issueCount - formIssues
+	fields["form_fraction"] = 0.0
+	if issueCount > 0 {
+		fields["form_fraction"] = float64(formIssues) / float64(issueCount)
+	}
+	a.AddCounter("github_issue_forms", fields, tags)
+}
+
+func (plugin *GitHub) getFirstTriageEvents(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, number int) (time.Time, time.Time, error) {
+	firstLabeled := time.Time{}
+	firstAssigned := time.Time{}
+	opts := &githubApi.ListOptions{PerPage: 100}
+	for {
+		events, response, err := client.Issues.ListIssueEvents(ctx, repoOwner, repoName, number, opts)
+		if err != nil {
+			return firstLabeled, firstAssigned, err
+		}
+		for _, event := range events {
+			switch event.GetEvent() {
+			case "labeled":
+				if firstLabeled.IsZero() || event.GetCreatedAt().Before(firstLabeled) {
+					firstLabeled = event.GetCreatedAt()
+				}
+			case "assigned":
+				if firstAssigned.IsZero() || event.GetCreatedAt().Before(firstAssigned) {
+					firstAssigned = event.GetCreatedAt()
+				}
+			}
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return firstLabeled, firstAssigned, nil
+}
+
+func average(sum float64, count int) float64 {
+	if count == 0 {
+		return 0.0
+	}
+	return sum / float64(count)
+}
+
+func (plugin *GitHub) processActivity(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, recentIssues []*githubApi.Issue) error {
+	since := time.Now().Add(-plugin.window)
+	counts := make(map[string]int)
+	for _, issue := range recentIssues {
+		kind := "issues"
+		if issue.IsPullRequest() {
+			kind = "pull_requests"
+		}
+		counts[kind+authorClass(issue.GetUser())]++
+	}
+	opts := &githubApi.IssueListCommentsOptions{Since: &since, ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		comments, response, err := client.Issues.ListComments(ctx, repoOwner, repoName, 0, opts)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if comment.GetCreatedAt().Before(since) {
+				continue
+			}
+			counts["comments"+authorClass(comment.GetUser())]++
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	for _, kind := range []string{"issues", "pull_requests", "comments"} {
+		fields[kind+"_human"] = counts[kind+"_human"]
+		fields[kind+"_bot"] = counts[kind+"_bot"]
+	}
+	a.AddCounter("github_activity", fields, tags)
+	return nil
+}
+
+func authorClass(user *githubApi.User) string {
+	if isBot(user) {
+		return "_bot"
+	}
+	return "_human"
+}
+
+func isBot(user *githubApi.User) bool {
+	return user.GetType() == "Bot" || strings.HasSuffix(user.GetLogin(), "[bot]")
+}
@@ -0,0 +1,80 @@
+// languages.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// processLanguageTrends sums up the language bytes of all (discovered) org repos and reports them together with the
+// change since the previous gather. Languages no longer present are reported once with zero bytes, so the delta of a
+// migrated away language is not lost. As this requires a request per repo, the language trends are only gathered once
+// per discovery interval.
+func (plugin *GitHub) processLanguageTrends(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	now := time.Now()
+	if gatheredAt, gathered := plugin.orgLanguagesAt[org]; gathered && now.Sub(gatheredAt) < plugin.discoveryInterval {
+		return nil
+	}
+	repos, err := plugin.discoverRepos(ctx, "languages:"+org, func() ([]string, error) {
+		return plugin.listOrgRepos(ctx, client, org)
+	})
+	if err != nil {
+		return err
+	}
+	languages := make(map[string]int)
+	for _, repo := range repos {
+		repoOwner, repoName, err := plugin.splitRepoId(repo)
+		if err != nil {
+			return err
+		}
+		repoLanguages, _, err := client.Repositories.ListLanguages(ctx, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+		for language, bytes := range repoLanguages {
+			languages[language] += bytes
+		}
+	}
+	totalBytes := 0
+	for _, bytes := range languages {
+		totalBytes += bytes
+	}
+	previousLanguages, previous := plugin.orgLanguages[org]
+	for language, bytes := range languages {
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["language"] = language
+		fields := make(map[string]interface{})
+		fields["bytes"] = bytes
+		fields["share_percent"] = float64(bytes) * 100.0 / float64(totalBytes)
+		if previous {
+			fields["bytes_delta"] = bytes - previousLanguages[language]
+		}
+		a.AddCounter("github_org_languages", fields, tags)
+	}
+	for language, previousBytes := range previousLanguages {
+		if _, present := languages[language]; present {
+			continue
+		}
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["language"] = language
+		fields := make(map[string]interface{})
+		fields["bytes"] = 0
+		fields["share_percent"] = 0.0
+		fields["bytes_delta"] = -previousBytes
+		a.AddCounter("github_org_languages", fields, tags)
+	}
+	plugin.orgLanguages[org] = languages
+	plugin.orgLanguagesAt[org] = now
+	return nil
+}
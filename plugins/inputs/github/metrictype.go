@@ -0,0 +1,82 @@
+// metrictype.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	metricTypeCounter = "counter"
+	metricTypeGauge   = "gauge"
+	metricTypeUntyped = "untyped"
+)
+
+// latestMetricVersion is the version of the current metric layout. Breaking metric layout changes (e.g. renamed fields or
+// split measurements) increment it, while the previous layouts remain available via the metric_version option. Version
+// 0 (the default) denotes the original layout, which is emitted without schema_version tag.
+const latestMetricVersion = 1
+
+func (plugin *GitHub) checkMetricType() error {
+	switch plugin.MetricType {
+	case metricTypeCounter, metricTypeGauge, metricTypeUntyped:
+		return nil
+	}
+	return fmt.Errorf("github: Invalid metric type '%s'", plugin.MetricType)
+}
+
+func (plugin *GitHub) checkMetricVersion() error {
+	if plugin.MetricVersion < 0 || plugin.MetricVersion > latestMetricVersion {
+		return fmt.Errorf("github: Invalid metric version %d", plugin.MetricVersion)
+	}
+	return nil
+}
+
+// metricTypeAccumulator emits the stats (which are added as counters by the collectors) using the configured metric
+// type instead.
+type metricTypeAccumulator struct {
+	telegraf.Accumulator
+	metricType string
+}
+
+func (acc *metricTypeAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	switch acc.metricType {
+	case metricTypeGauge:
+		acc.Accumulator.AddGauge(measurement, fields, tags, t...)
+	case metricTypeUntyped:
+		acc.Accumulator.AddFields(measurement, fields, tags, t...)
+	default:
+		acc.Accumulator.AddCounter(measurement, fields, tags, t...)
+	}
+}
+
+// schemaVersionAccumulator attaches the schema_version tag to all metrics.
+type schemaVersionAccumulator struct {
+	telegraf.Accumulator
+	schemaVersion string
+}
+
+func (acc *schemaVersionAccumulator) tag(tags map[string]string) map[string]string {
+	tags["schema_version"] = acc.schemaVersion
+	return tags
+}
+
+func (acc *schemaVersionAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddFields(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *schemaVersionAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddGauge(measurement, fields, acc.tag(tags), t...)
+}
+
+func (acc *schemaVersionAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	acc.Accumulator.AddCounter(measurement, fields, acc.tag(tags), t...)
+}
@@ -0,0 +1,83 @@
+// mirrors.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// processReleaseMirrors probes the configured release mirrors for the latest release's assets (as counted for the download
+// stats) via HEAD requests. Mirror URLs are given as templates containing the {owner}, {repo}, {tag} and {asset}
+// placeholders.
+func (plugin *GitHub) processReleaseMirrors(ctx context.Context, a telegraf.Accumulator, repo string, repoOwner string, repoName string, latest *githubApi.RepositoryRelease) {
+	httpClient := plugin.externalClient
+	for _, mirror := range plugin.ReleaseMirrors {
+		assets := 0
+		presentAssets := 0
+		for _, asset := range latest.Assets {
+			if !plugin.countAsset(asset.GetName()) {
+				continue
+			}
+			assets++
+			replacer := strings.NewReplacer(
+				"{owner}", url.PathEscape(repoOwner),
+				"{repo}", url.PathEscape(repoName),
+				"{tag}", url.PathEscape(latest.GetTagName()),
+				"{asset}", url.PathEscape(asset.GetName()))
+			assetURL := replacer.Replace(mirror)
+			if plugin.probeMirrorAsset(ctx, httpClient, assetURL) {
+				presentAssets++
+			}
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_release"] = latest.GetTagName()
+		tags["mirror"] = mirrorHost(mirror)
+		fields := make(map[string]interface{})
+		fields["assets"] = assets
+		fields["present_assets"] = presentAssets
+		fields["missing_assets"] = assets - presentAssets
+		fields["in_sync"] = presentAssets == assets
+		a.AddCounter("github_release_mirror", fields, tags)
+	}
+}
+
+func (plugin *GitHub) probeMirrorAsset(ctx context.Context, httpClient *http.Client, assetURL string) bool {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, assetURL, nil)
+	if err != nil {
+		plugin.Log.Warnf("Invalid mirror URL '%s': %v", assetURL, err)
+		return false
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		if plugin.Debug {
+			plugin.Log.Debugf("Mirror request '%s' failed: %v", assetURL, err)
+		}
+		return false
+	}
+	response.Body.Close()
+	if plugin.Debug {
+		plugin.Log.Debugf("Mirror request '%s' returned status %d", assetURL, response.StatusCode)
+	}
+	return response.StatusCode >= 200 && response.StatusCode < 300
+}
+
+// mirrorHost gets the host of a mirror URL template (falling back to the template itself, if it cannot be parsed).
+func mirrorHost(mirror string) string {
+	mirrorURL, err := url.Parse(mirror)
+	if err != nil || mirrorURL.Host == "" {
+		return mirror
+	}
+	return mirrorURL.Host
+}
@@ -0,0 +1,101 @@
+// orgs.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processOrg(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	if plugin.Debug {
+		plugin.Log.Infof("Processing org: %s", org)
+	}
+	var usage *billingUsage
+	if plugin.ActionsUsage || plugin.ActionsLeaderboard > 0 || plugin.LFSUsage {
+		var err error
+		usage, err = plugin.getBillingUsage(ctx, client, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.ActionsUsage {
+		plugin.processActionsUsage(a, org, usage)
+	}
+	if plugin.ActionsLeaderboard > 0 {
+		plugin.processActionsLeaderboard(a, org, usage)
+	}
+	if plugin.CopilotUsage {
+		err := plugin.processCopilotUsage(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.AuditLog {
+		err := plugin.processAuditLogSummary(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.IPAllowList {
+		err := plugin.processIPAllowList(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.SSOCredentials {
+		err := plugin.processCredentialAuthorizations(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.PATRequests {
+		err := plugin.processPATRequests(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.OrgWebhooks {
+		err := plugin.processOrgWebhooks(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.LanguageTrends {
+		err := plugin.processLanguageTrends(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.LFSUsage {
+		plugin.processLFSUsage(a, org, usage)
+	}
+	if plugin.ClassicProjects {
+		err := plugin.processOrgClassicProjects(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if plugin.Rulesets {
+		err := plugin.processOrgRulesets(ctx, client, a, org)
+		if err != nil {
+			return err
+		}
+	}
+	if len(plugin.GraphQLQueries) > 0 {
+		scopeTags := map[string]string{"github_org": org}
+		scopeVariables := map[string]interface{}{"org": org}
+		err := plugin.processGraphQLQueries(ctx, client, a, graphQLQueryScopeOrg, scopeTags, scopeVariables)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// paths.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processPathActivity(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, recentIssues []*githubApi.Issue) error {
+	since := time.Now().Add(-plugin.window)
+	pathPullRequests, err := plugin.countPathPullRequests(ctx, client, repo, repoOwner, repoName, recentIssues)
+	if err != nil {
+		return err
+	}
+	for _, path := range plugin.ActivityPaths {
+		commits := 0
+		opts := &githubApi.CommitsListOptions{Path: path, Since: since, ListOptions: githubApi.ListOptions{PerPage: 100}}
+		for {
+			pathCommits, response, err := client.Repositories.ListCommits(ctx, repoOwner, repoName, opts)
+			if err != nil {
+				return err
+			}
+			commits += len(pathCommits)
+			if response.NextPage == 0 {
+				break
+			}
+			opts.Page = response.NextPage
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["path"] = path
+		fields := make(map[string]interface{})
+		fields["commits"] = commits
+		fields["pull_requests"] = pathPullRequests[path]
+		a.AddCounter("github_path_activity", fields, tags)
+	}
+	return nil
+}
+
+// closedPullRequestPaths holds the configured paths touched by a closed pull request. As the files of closed pull requests
+// do not change, they are remembered (for as long as the pull request is within the window) instead of being listed on
+// every gather.
+type closedPullRequestPaths struct {
+	closedAt time.Time
+	paths    map[string]bool
+}
+
+// countPathPullRequests counts the given recent pull requests touching each of the configured paths.
+func (plugin *GitHub) countPathPullRequests(ctx context.Context, client *githubApi.Client, repo string, repoOwner string, repoName string, recentIssues []*githubApi.Issue) (map[string]int, error) {
+	pathPullRequests := make(map[string]int)
+	knownPaths := plugin.closedPullRequestPaths(repo)
+	closedPaths := make(map[int]*closedPullRequestPaths)
+	for _, issue := range recentIssues {
+		if !issue.IsPullRequest() {
+			continue
+		}
+		touched := knownPaths[issue.GetNumber()]
+		if issue.GetState() != "closed" || touched == nil || !touched.closedAt.Equal(issue.GetClosedAt()) {
+			touchedPaths, err := plugin.listTouchedPaths(ctx, client, repoOwner, repoName, issue.GetNumber())
+			if err != nil {
+				return nil, err
+			}
+			touched = &closedPullRequestPaths{closedAt: issue.GetClosedAt(), paths: touchedPaths}
+		}
+		if issue.GetState() == "closed" {
+			closedPaths[issue.GetNumber()] = touched
+		}
+		for path := range touched.paths {
+			pathPullRequests[path]++
+		}
+	}
+	plugin.updateClosedPullRequestPaths(repo, closedPaths)
+	return pathPullRequests, nil
+}
+
+// listTouchedPaths determines the configured paths touched by the given pull request.
+func (plugin *GitHub) listTouchedPaths(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, number int) (map[string]bool, error) {
+	touchedPaths := make(map[string]bool)
+	opts := &githubApi.ListOptions{PerPage: 100}
+	for {
+		files, response, err := client.PullRequests.ListFiles(ctx, repoOwner, repoName, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			for _, path := range plugin.ActivityPaths {
+				if file.GetFilename() == path || strings.HasPrefix(file.GetFilename(), strings.TrimSuffix(path, "/")+"/") {
+					touchedPaths[path] = true
+				}
+			}
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return touchedPaths, nil
+}
+
+func (plugin *GitHub) closedPullRequestPaths(repo string) map[int]*closedPullRequestPaths {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	return plugin.pullRequestPaths[repo]
+}
+
+// updateClosedPullRequestPaths replaces the remembered paths of the repo's closed pull requests (thereby dropping the
+// ones of pull requests no longer within the window).
+func (plugin *GitHub) updateClosedPullRequestPaths(repo string, closedPaths map[int]*closedPullRequestPaths) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	plugin.pullRequestPaths[repo] = closedPaths
+}
@@ -0,0 +1,68 @@
+// projects.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"net/http"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+type listProjectsFunc func(opts *githubApi.ProjectListOptions) ([]*githubApi.Project, *githubApi.Response, error)
+
+func (plugin *GitHub) processRepoClassicProjects(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	return plugin.processClassicProjects(a, tags, func(opts *githubApi.ProjectListOptions) ([]*githubApi.Project, *githubApi.Response, error) {
+		return client.Repositories.ListProjects(ctx, repoOwner, repoName, opts)
+	})
+}
+
+func (plugin *GitHub) processOrgClassicProjects(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	return plugin.processClassicProjects(a, tags, func(opts *githubApi.ProjectListOptions) ([]*githubApi.Project, *githubApi.Response, error) {
+		return client.Organizations.ListProjects(ctx, org, opts)
+	})
+}
+
+// processClassicProjects counts the remaining (legacy) classic projects. Disabled or already sunset projects (404
+// respectively 410 responses) are counted as none.
+func (plugin *GitHub) processClassicProjects(a telegraf.Accumulator, tags map[string]string, listProjects listProjectsFunc) error {
+	open := 0
+	closed := 0
+	opts := &githubApi.ProjectListOptions{State: "all", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		projects, response, err := listProjects(opts)
+		if err != nil {
+			if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone) {
+				break
+			}
+			return err
+		}
+		for _, project := range projects {
+			if project.GetState() == "closed" {
+				closed++
+			} else {
+				open++
+			}
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	fields := make(map[string]interface{})
+	fields["projects"] = open + closed
+	fields["open_projects"] = open
+	fields["closed_projects"] = closed
+	a.AddCounter("github_classic_projects", fields, tags)
+	return nil
+}
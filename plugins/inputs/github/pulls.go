@@ -0,0 +1,172 @@
+// pulls.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// listOpenPullRequests lists all currently open pull requests.
+func (plugin *GitHub) listOpenPullRequests(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string) ([]*githubApi.PullRequest, error) {
+	openPullRequests := make([]*githubApi.PullRequest, 0)
+	opts := &githubApi.PullRequestListOptions{State: "open", ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		pullRequests, response, err := client.PullRequests.List(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		openPullRequests = append(openPullRequests, pullRequests...)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return openPullRequests, nil
+}
+
+func (plugin *GitHub) processDependencyPullRequests(a telegraf.Accumulator, repo string, openPullRequests []*githubApi.PullRequest) {
+	now := time.Now()
+	bots := make(map[string]bool)
+	for _, bot := range plugin.DependencyBots {
+		bots[strings.ToLower(bot)] = true
+	}
+	openDependencyPullRequests := 0
+	var maxAgeSeconds int64
+	for _, pullRequest := range openPullRequests {
+		if !bots[strings.ToLower(pullRequest.GetUser().GetLogin())] {
+			continue
+		}
+		openDependencyPullRequests++
+		ageSeconds := int64(now.Sub(pullRequest.GetCreatedAt()).Seconds())
+		if ageSeconds > maxAgeSeconds {
+			maxAgeSeconds = ageSeconds
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["open_pull_requests"] = openDependencyPullRequests
+	fields["max_age_seconds"] = maxAgeSeconds
+	a.AddCounter("github_dependency_pull_requests", fields, tags)
+}
+
+// otherBranches is the branch tag value used for the open pull requests of the branches beyond the configured limit.
+const otherBranches = "<other>"
+
+// processPullRequestBranches counts the open pull requests per base branch. Only the branches with the most open pull
+// requests are reported individually; the remaining ones are summed up.
+func (plugin *GitHub) processPullRequestBranches(a telegraf.Accumulator, repo string, defaultBranch string, openPullRequests []*githubApi.PullRequest) {
+	branchCounts := make(map[string]int)
+	for _, pullRequest := range openPullRequests {
+		branchCounts[pullRequest.GetBase().GetRef()]++
+	}
+	branches := make([]string, 0, len(branchCounts))
+	for branch := range branchCounts {
+		branches = append(branches, branch)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		if branchCounts[branches[i]] != branchCounts[branches[j]] {
+			return branchCounts[branches[i]] > branchCounts[branches[j]]
+		}
+		return branches[i] < branches[j]
+	})
+	otherPullRequests := 0
+	for i, branch := range branches {
+		if plugin.MaxPullRequestBranches > 0 && i >= plugin.MaxPullRequestBranches {
+			otherPullRequests += branchCounts[branch]
+			continue
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_branch"] = branch
+		fields := make(map[string]interface{})
+		fields["open_pull_requests"] = branchCounts[branch]
+		fields["default_branch"] = branch == defaultBranch
+		a.AddCounter("github_pull_request_branch", fields, tags)
+	}
+	if otherPullRequests > 0 {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_branch"] = otherBranches
+		fields := make(map[string]interface{})
+		fields["open_pull_requests"] = otherPullRequests
+		fields["default_branch"] = false
+		a.AddCounter("github_pull_request_branch", fields, tags)
+	}
+}
+
+const pullRequestMergeableQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(states: OPEN, first: 100, after: $cursor) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+      nodes {
+        mergeable
+      }
+    }
+  }
+}`
+
+type pullRequestMergeableResult struct {
+	Repository struct {
+		PullRequests struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Mergeable string `json:"mergeable"`
+			} `json:"nodes"`
+		} `json:"pullRequests"`
+	} `json:"repository"`
+}
+
+// processMergeConflicts counts the open pull requests currently conflicting with their base branch. The mergeable
+// state is computed lazily by GitHub; pull requests with a not yet computed state are counted separately.
+func (plugin *GitHub) processMergeConflicts(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	openPullRequests := 0
+	conflictingPullRequests := 0
+	unknownPullRequests := 0
+	variables := map[string]interface{}{"owner": repoOwner, "name": repoName}
+	for {
+		result := &pullRequestMergeableResult{}
+		err := plugin.queryGraphQL(ctx, client, pullRequestMergeableQuery, variables, result)
+		if err != nil {
+			return err
+		}
+		for _, pullRequest := range result.Repository.PullRequests.Nodes {
+			openPullRequests++
+			switch pullRequest.Mergeable {
+			case "CONFLICTING":
+				conflictingPullRequests++
+			case "UNKNOWN":
+				unknownPullRequests++
+			}
+		}
+		if !result.Repository.PullRequests.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = result.Repository.PullRequests.PageInfo.EndCursor
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["open_pull_requests"] = openPullRequests
+	fields["conflicting_pull_requests"] = conflictingPullRequests
+	fields["unknown_pull_requests"] = unknownPullRequests
+	a.AddCounter("github_merge_conflicts", fields, tags)
+	return nil
+}
@@ -0,0 +1,180 @@
+// ratelimit.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	rateLimitsSourceHeaders  = "headers"
+	rateLimitsSourceEndpoint = "endpoint"
+)
+
+// rateLimitsResources represents the rate limit endpoint's response (the go-github API version in use does not cover the
+// graphql resource).
+type rateLimitsResources struct {
+	Resources map[string]*githubApi.Rate `json:"resources"`
+}
+
+// rateLimitUsage accumulates the rate limit consumption of a single gather based on the X-RateLimit-Used response
+// headers (tracked per rate limit resource, as the core, search and graphql limits are counted separately).
+type rateLimitUsage struct {
+	mutex     sync.Mutex
+	used      map[string]int
+	remaining map[string]int
+	limits    map[string]githubApi.Rate
+	cost      int
+}
+
+func newRateLimitUsage() *rateLimitUsage {
+	return &rateLimitUsage{used: make(map[string]int), remaining: make(map[string]int), limits: make(map[string]githubApi.Rate)}
+}
+
+func (usage *rateLimitUsage) update(response *http.Response) {
+	used, err := strconv.Atoi(response.Header.Get("X-RateLimit-Used"))
+	if err != nil {
+		return
+	}
+	resource := response.Header.Get("X-RateLimit-Resource")
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	remaining, err := strconv.Atoi(response.Header.Get("X-RateLimit-Remaining"))
+	if err == nil {
+		usage.remaining[resource] = remaining
+		limit, err := strconv.Atoi(response.Header.Get("X-RateLimit-Limit"))
+		if err == nil {
+			reset, _ := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+			usage.limits[resource] = githubApi.Rate{Limit: limit, Remaining: remaining, Reset: githubApi.Timestamp{Time: time.Unix(reset, 0)}}
+		}
+	}
+	previous, known := usage.used[resource]
+	switch {
+	case !known:
+		// no reference yet; at least this request has been counted
+		usage.cost++
+	case used > previous:
+		usage.cost += used - previous
+	case used < previous:
+		// the rate limit window has been reset in between
+		usage.cost += used
+	}
+	usage.used[resource] = used
+}
+
+// exhausted reports whether the last reported remaining rate limit of the given resource is below the given number of
+// requests.
+func (usage *rateLimitUsage) exhausted(resource string, requests int) bool {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	remaining, known := usage.remaining[resource]
+	return known && remaining < requests
+}
+
+// rateLimitThrottled reports whether the remaining core rate limit has fallen below the configured threshold, in which case
+// only the basic repo stats are gathered.
+func (plugin *GitHub) rateLimitThrottled() bool {
+	return plugin.RateLimitThreshold > 0 && plugin.rateLimitUsage != nil && plugin.rateLimitUsage.exhausted("core", plugin.RateLimitThreshold)
+}
+
+func (usage *rateLimitUsage) total() int {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	return usage.cost
+}
+
+// lastLimits gets the last reported rate limit per resource.
+func (usage *rateLimitUsage) lastLimits() map[string]githubApi.Rate {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	limits := make(map[string]githubApi.Rate)
+	for resource, limit := range usage.limits {
+		limits[resource] = limit
+	}
+	return limits
+}
+
+func (plugin *GitHub) checkRateLimitsSource() error {
+	switch plugin.RateLimitsSource {
+	case rateLimitsSourceHeaders, rateLimitsSourceEndpoint:
+		return nil
+	}
+	return fmt.Errorf("github: Invalid rate limits source '%s'", plugin.RateLimitsSource)
+}
+
+// processRateLimits reports the rate limits either as last seen in the gather's response headers (covering only the
+// resources actually used) or as returned by the rate limit endpoint (which itself does not count against the rate limit).
+func (plugin *GitHub) processRateLimits(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator) error {
+	var limits map[string]githubApi.Rate
+	if plugin.RateLimitsSource == rateLimitsSourceEndpoint {
+		request, err := client.NewRequest("GET", "rate_limit", nil)
+		if err != nil {
+			return err
+		}
+		var rateLimits rateLimitsResources
+		_, err = client.Do(ctx, request, &rateLimits)
+		if err != nil {
+			return err
+		}
+		limits = make(map[string]githubApi.Rate)
+		for _, resource := range []string{"core", "search", "graphql"} {
+			limit := rateLimits.Resources[resource]
+			if limit != nil {
+				limits[resource] = *limit
+			}
+		}
+	} else {
+		limits = plugin.rateLimitUsage.lastLimits()
+	}
+	now := time.Now()
+	for resource, limit := range limits {
+		if resource == "" {
+			continue
+		}
+		tags := make(map[string]string)
+		tags["resource"] = resource
+		fields := make(map[string]interface{})
+		fields["limit"] = limit.Limit
+		fields["remaining"] = limit.Remaining
+		resetSeconds := int(limit.Reset.Sub(now).Seconds())
+		if resetSeconds < 0 {
+			resetSeconds = 0
+		}
+		fields["reset_seconds"] = resetSeconds
+		a.AddCounter("github_rate_limit", fields, tags)
+	}
+	return nil
+}
+
+// observingTransport feeds all responses into the rate limit usage, back-off and gather summary tracking.
+type observingTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *observingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := transport.base.RoundTrip(request)
+	if transport.plugin.gatherSummary != nil {
+		transport.plugin.gatherSummary.recordCall(request, response, err)
+	}
+	if err == nil {
+		if transport.plugin.rateLimitUsage != nil {
+			transport.plugin.rateLimitUsage.update(response)
+		}
+		transport.plugin.backoffState.update(response)
+	}
+	return response, err
+}
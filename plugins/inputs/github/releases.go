@@ -0,0 +1,440 @@
+// releases.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// processReleases gathers the release based stats (adding the download stats to the given repo info fields). The
+// releases are listed unless they have already been prefetched.
+func (plugin *GitHub) processReleases(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, prefetchedReleases []*githubApi.RepositoryRelease, fields map[string]interface{}) error {
+	repoReleases := prefetchedReleases
+	var err error
+	if repoReleases == nil {
+		repoReleases, err = plugin.listReleases(ctx, client, repoOwner, repoName)
+		if err != nil {
+			return err
+		}
+	}
+	totalDownloadCount := 0
+	botDownloadCount := 0
+	for _, repoRelease := range repoReleases {
+		if plugin.countRelease(repoRelease) {
+			totalDownloadCount += plugin.releaseDownloadCount(repoRelease)
+			botDownloadCount += plugin.releaseBotDownloadCount(repoRelease)
+		}
+	}
+	var latestReleaseDownloadsPerDay float64
+	if latest := plugin.latestRelease(repoReleases); latest != nil {
+		latestReleaseDownloadsPerDay = plugin.releaseDownloadsPerDay(latest, time.Now())
+		if plugin.LatestRelease {
+			plugin.processLatestRelease(a, repo, latest, time.Now())
+		}
+		if len(plugin.ReleaseMirrors) > 0 {
+			plugin.processReleaseMirrors(ctx, a, repo, repoOwner, repoName, latest)
+		}
+	}
+	if plugin.ReleaseCadence {
+		plugin.processReleaseCadence(a, repo, repoReleases, time.Now())
+	}
+	if plugin.ReleaseNotes {
+		plugin.processReleaseNotes(a, repo, repoReleases, time.Now())
+	}
+	if plugin.ReleaseStats {
+		plugin.processReleaseStats(a, repo, repoReleases)
+	}
+	if plugin.ReleaseAssets {
+		plugin.processReleaseAssets(a, repo, repoReleases)
+	}
+	if plugin.ReleaseAssetTypes {
+		plugin.processReleaseAssetTypes(a, repo, repoReleases)
+	}
+	if plugin.ReleaseDigests {
+		plugin.processReleaseDigests(a, repo, repoReleases)
+	}
+	if plugin.ReleaseSignatures {
+		plugin.processReleaseSignatures(a, repo, repoReleases)
+	}
+	if plugin.TagCoverage && !plugin.isGitea() && !plugin.Anonymous {
+		err = plugin.processTagCoverage(ctx, client, a, repo, repoOwner, repoName, repoReleases)
+		if err != nil {
+			return err
+		}
+	}
+	if len(plugin.MaintenanceBranches) > 0 && !plugin.isGitea() && !plugin.Anonymous {
+		err = plugin.processMaintenanceBranches(ctx, client, a, repo, repoOwner, repoName, repoReleases)
+		if err != nil {
+			return err
+		}
+	}
+	fields["total_download_count"] = totalDownloadCount
+	if plugin.ExcludeBotAssets {
+		fields["bot_download_count"] = botDownloadCount
+	}
+	fields["latest_release_downloads_per_day"] = latestReleaseDownloadsPerDay
+	return nil
+}
+
+// listReleases lists the repo's releases (newest first) up to the configured page limit.
+func (plugin *GitHub) listReleases(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string) ([]*githubApi.RepositoryRelease, error) {
+	releases := make([]*githubApi.RepositoryRelease, 0)
+	maxPages := plugin.MaxReleasePages
+	if plugin.Anonymous {
+		maxPages = 1
+	}
+	opts := &githubApi.ListOptions{PerPage: 100}
+	for page := 1; ; page++ {
+		pageReleases, response, err := client.Repositories.ListReleases(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, pageReleases...)
+		if response.NextPage == 0 {
+			break
+		}
+		if maxPages > 0 && page >= maxPages {
+			plugin.Log.Warnf("Release list of repo %s/%s exceeds %d pages; ignoring remaining releases", repoOwner, repoName, maxPages)
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return releases, nil
+}
+
+// countRelease reports whether the release's downloads count towards the total download count.
+func (plugin *GitHub) countRelease(repoRelease *githubApi.RepositoryRelease) bool {
+	return (plugin.IncludePrereleases || !repoRelease.GetPrerelease()) && (plugin.IncludeDrafts || !repoRelease.GetDraft())
+}
+
+func (plugin *GitHub) processReleaseStats(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease) {
+	for _, repoRelease := range repoReleases {
+		if repoRelease.GetDraft() {
+			continue
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_release"] = repoRelease.GetTagName()
+		fields := make(map[string]interface{})
+		fields["download_count"] = plugin.releaseDownloadCount(repoRelease)
+		fields["asset_count"] = len(repoRelease.Assets)
+		fields["published_at"] = repoRelease.GetPublishedAt().Unix()
+		a.AddCounter("github_release", fields, tags)
+	}
+}
+
+func (plugin *GitHub) processLatestRelease(a telegraf.Accumulator, repo string, latest *githubApi.RepositoryRelease, now time.Time) {
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	tags["github_release"] = latest.GetTagName()
+	fields := make(map[string]interface{})
+	fields["latest_release_downloads"] = plugin.releaseDownloadCount(latest)
+	fields["latest_release_age_days"] = now.Sub(latest.GetPublishedAt().Time).Hours() / 24.0
+	fields["latest_release_published_at"] = latest.GetPublishedAt().Unix()
+	a.AddCounter("github_latest_release", fields, tags)
+}
+
+// releaseCadencePeriod defines the period to count the recent releases for.
+const releaseCadencePeriod = 90 * 24 * time.Hour
+
+func (plugin *GitHub) processReleaseCadence(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease, now time.Time) {
+	periodStart := now.Add(-releaseCadencePeriod)
+	recentReleases := 0
+	for _, repoRelease := range repoReleases {
+		if plugin.publishedRelease(repoRelease) && repoRelease.GetPublishedAt().After(periodStart) {
+			recentReleases++
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["releases_last_90d"] = recentReleases
+	latest := plugin.latestRelease(repoReleases)
+	fields["has_release"] = latest != nil
+	if latest != nil {
+		fields["days_since_last_release"] = now.Sub(latest.GetPublishedAt().Time).Hours() / 24.0
+	}
+	a.AddCounter("github_release_cadence", fields, tags)
+}
+
+// processReleaseNotes counts the releases published within the window without release notes (an empty body).
+func (plugin *GitHub) processReleaseNotes(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease, now time.Time) {
+	windowStart := now.Add(-plugin.window)
+	releases := 0
+	releasesWithoutBody := 0
+	for _, repoRelease := range repoReleases {
+		if repoRelease.GetDraft() || repoRelease.PublishedAt == nil || repoRelease.GetPublishedAt().Before(windowStart) {
+			continue
+		}
+		releases++
+		if strings.TrimSpace(repoRelease.GetBody()) == "" {
+			releasesWithoutBody++
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["releases"] = releases
+	fields["releases_without_body"] = releasesWithoutBody
+	a.AddCounter("github_release_notes", fields, tags)
+}
+
+func (plugin *GitHub) processReleaseAssets(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease) {
+	for _, repoRelease := range repoReleases {
+		if repoRelease.GetDraft() {
+			continue
+		}
+		for _, asset := range repoRelease.Assets {
+			tags := make(map[string]string)
+			tags["github_repo"] = repo
+			tags["github_release"] = repoRelease.GetTagName()
+			tags["github_asset"] = asset.GetName()
+			fields := make(map[string]interface{})
+			fields["download_count"] = asset.GetDownloadCount()
+			fields["size"] = asset.GetSize()
+			a.AddCounter("github_release_asset", fields, tags)
+		}
+	}
+}
+
+func (plugin *GitHub) processReleaseDigests(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease) {
+	for _, repoRelease := range repoReleases {
+		if repoRelease.GetDraft() {
+			continue
+		}
+		digest := plugin.releaseAssetsDigest(repoRelease)
+		digestKey := fmt.Sprintf("%s#%d", repo, repoRelease.GetID())
+		previousDigest, known := plugin.swapReleaseDigest(digestKey, digest)
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_release"] = repoRelease.GetTagName()
+		fields := make(map[string]interface{})
+		fields["assets_digest"] = digest
+		fields["assets_replaced"] = known && previousDigest != digest
+		a.AddCounter("github_release_digest", fields, tags)
+	}
+}
+
+// releaseAssetsDigest computes a change-detection hash over the release's assets. As a replaced asset gets a new id and
+// update timestamp, any replacement of an existing release asset results in a different digest.
+func (plugin *GitHub) releaseAssetsDigest(repoRelease *githubApi.RepositoryRelease) string {
+	assets := make([]string, 0, len(repoRelease.Assets))
+	for _, asset := range repoRelease.Assets {
+		assets = append(assets, fmt.Sprintf("%d:%s:%d:%s", asset.GetID(), asset.GetName(), asset.GetSize(), asset.GetUpdatedAt().UTC().Format("2006-01-02T15:04:05Z")))
+	}
+	sort.Strings(assets)
+	hash := sha256.New()
+	for _, asset := range assets {
+		hash.Write([]byte(asset))
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (plugin *GitHub) processReleaseSignatures(a telegraf.Accumulator, repo string, repoReleases []*githubApi.RepositoryRelease) {
+	releases := 0
+	signedReleases := 0
+	sbomReleases := 0
+	provenanceReleases := 0
+	for _, repoRelease := range repoReleases {
+		if repoRelease.GetDraft() {
+			continue
+		}
+		releases++
+		if plugin.releaseHasAsset(repoRelease, plugin.SignaturePatterns) {
+			signedReleases++
+		}
+		if plugin.releaseHasAsset(repoRelease, plugin.SBOMPatterns) {
+			sbomReleases++
+		}
+		if plugin.releaseHasAsset(repoRelease, plugin.ProvenancePatterns) {
+			provenanceReleases++
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["releases"] = releases
+	fields["signed_releases"] = signedReleases
+	fields["sbom_releases"] = sbomReleases
+	fields["provenance_releases"] = provenanceReleases
+	a.AddCounter("github_release_signatures", fields, tags)
+}
+
+func (plugin *GitHub) releaseHasAsset(repoRelease *githubApi.RepositoryRelease, patterns []string) bool {
+	for _, asset := range repoRelease.Assets {
+		if matchAssetName(asset.GetName(), patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAssetName(name string, patterns []string) bool {
+	lowerName := strings.ToLower(name)
+	for _, pattern := range patterns {
+		matched, _ := path.Match(strings.ToLower(pattern), lowerName)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultBotAssets matches the asset files typically fetched by automation (e.g. install scripts verifying a download)
+// rather than by users.
+var defaultBotAssets = []string{"*.sha256", "*.sha512", "*.md5", "*checksums.txt", "*.sig", "*.asc", "*.pem", "*.sbom.json", "*.spdx.json", "*.intoto.jsonl"}
+
+// publishedRelease reports whether the release counts as published release for the latest release and the release
+// cadence. Drafts are always ignored, pre-releases unless they are included via include_prereleases.
+func (plugin *GitHub) publishedRelease(repoRelease *githubApi.RepositoryRelease) bool {
+	return !repoRelease.GetDraft() && (plugin.IncludePrereleases || !repoRelease.GetPrerelease()) && repoRelease.PublishedAt != nil
+}
+
+// latestRelease returns the most recently published release (see publishedRelease) or nil if there is none.
+func (plugin *GitHub) latestRelease(repoReleases []*githubApi.RepositoryRelease) *githubApi.RepositoryRelease {
+	var latest *githubApi.RepositoryRelease
+	for _, repoRelease := range repoReleases {
+		if !plugin.publishedRelease(repoRelease) {
+			continue
+		}
+		if latest == nil || repoRelease.GetPublishedAt().After(latest.GetPublishedAt().Time) {
+			latest = repoRelease
+		}
+	}
+	return latest
+}
+
+// releaseDownloadCount sums up the download counts of the release's assets matching the asset include/exclude patterns.
+func (plugin *GitHub) releaseDownloadCount(repoRelease *githubApi.RepositoryRelease) int {
+	downloadCount := 0
+	for _, asset := range repoRelease.Assets {
+		if plugin.countAsset(asset.GetName()) {
+			downloadCount += asset.GetDownloadCount()
+		}
+	}
+	return downloadCount
+}
+
+// releaseBotDownloadCount sums up the download counts of the release's bot assets (if these are excluded from the regular
+// download counts).
+func (plugin *GitHub) releaseBotDownloadCount(repoRelease *githubApi.RepositoryRelease) int {
+	downloadCount := 0
+	if plugin.ExcludeBotAssets {
+		for _, asset := range repoRelease.Assets {
+			if matchAssetName(asset.GetName(), plugin.BotAssets) {
+				downloadCount += asset.GetDownloadCount()
+			}
+		}
+	}
+	return downloadCount
+}
+
+func (plugin *GitHub) countAsset(name string) bool {
+	if len(plugin.AssetInclude) > 0 && !matchAssetName(name, plugin.AssetInclude) {
+		return false
+	}
+	if plugin.ExcludeBotAssets && matchAssetName(name, plugin.BotAssets) {
+		return false
+	}
+	return !matchAssetName(name, plugin.AssetExclude)
+}
+
+// releaseDownloadsPerDay computes the release's average downloads per day since it has been published (counting at least one day).
+func (plugin *GitHub) releaseDownloadsPerDay(repoRelease *githubApi.RepositoryRelease, now time.Time) float64 {
+	days := now.Sub(repoRelease.GetPublishedAt().Time).Hours() / 24.0
+	if days < 1.0 {
+		days = 1.0
+	}
+	return float64(plugin.releaseDownloadCount(repoRelease)) / days
+}
+
+// maxMaintenanceBranchCandidates limits the number of releases (newest first) checked for being contained in a
+// maintenance branch, as every check requires a compare request.
+const maxMaintenanceBranchCandidates = 10
+
+// processMaintenanceBranches reports the latest release contained in each maintenance branch. As the releases' target
+// commitish only reflects the branch the release was created from (if at all), the compare API is used to determine
+// whether a branch contains a release's tag (the branch being ahead of or identical to the tag). Releases found not to
+// be contained are remembered and not compared again, hence once the branch's latest release is known, only this
+// release is compared on subsequent gathers (to get the commits since the release).
+func (plugin *GitHub) processMaintenanceBranches(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, repoReleases []*githubApi.RepositoryRelease) error {
+	now := time.Now()
+	candidates := make([]*githubApi.RepositoryRelease, 0, len(repoReleases))
+	for _, repoRelease := range repoReleases {
+		if !repoRelease.GetDraft() && repoRelease.PublishedAt != nil {
+			candidates = append(candidates, repoRelease)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].GetPublishedAt().After(candidates[j].GetPublishedAt().Time)
+	})
+	if len(candidates) > maxMaintenanceBranchCandidates {
+		candidates = candidates[:maxMaintenanceBranchCandidates]
+	}
+	for _, branch := range plugin.MaintenanceBranches {
+		var branchRelease *githubApi.RepositoryRelease
+		var comparison *githubApi.CommitsComparison
+		for _, candidate := range candidates {
+			checkKey := repo + "@" + branch + "#" + candidate.GetTagName()
+			if plugin.branchReleaseExcluded(checkKey) {
+				continue
+			}
+			candidateComparison, response, err := client.Repositories.CompareCommits(ctx, repoOwner, repoName, candidate.GetTagName(), branch, nil)
+			if err != nil {
+				// missing tags or branches have nothing to compare
+				if response != nil && response.StatusCode == http.StatusNotFound {
+					continue
+				}
+				return err
+			}
+			if candidateComparison.GetStatus() == "ahead" || candidateComparison.GetStatus() == "identical" {
+				branchRelease = candidate
+				comparison = candidateComparison
+				break
+			}
+			plugin.excludeBranchRelease(checkKey)
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_branch"] = branch
+		fields := make(map[string]interface{})
+		fields["has_release"] = branchRelease != nil
+		if branchRelease != nil {
+			fields["latest_release"] = branchRelease.GetTagName()
+			fields["latest_release_age_days"] = now.Sub(branchRelease.GetPublishedAt().Time).Hours() / 24.0
+			fields["commits_since_release"] = comparison.GetAheadBy()
+		}
+		a.AddCounter("github_branch_release", fields, tags)
+	}
+	return nil
+}
+
+// branchReleaseExcluded reports whether the release (<repo>@<branch>#<tag>) is known not to be contained in the branch.
+func (plugin *GitHub) branchReleaseExcluded(checkKey string) bool {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	return plugin.excludedReleases[checkKey]
+}
+
+func (plugin *GitHub) excludeBranchRelease(checkKey string) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	plugin.excludedReleases[checkKey] = true
+}
@@ -0,0 +1,117 @@
+// retry.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryBaseDelay is the delay before the first retry, which doubles with every further retry.
+const retryBaseDelay = time.Second
+
+// retryingTransport retries requests failing with a transient server side failure (5xx responses as well as secondary
+// rate limit responses). The delay requested via the Retry-After header (or the rate limit reset time) takes precedence
+// over the exponentially growing default delay. Failures requiring a longer delay than the configured maximum are
+// returned immediately. The configured timeout applies to every single attempt (instead of the client as a whole),
+// hence the delays between the attempts do not count towards it.
+type retryingTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *retryingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := transport.roundTripAttempt(request)
+		if err != nil || attempt >= transport.plugin.Retries || !isIncidentResponse(response) {
+			return response, err
+		}
+		delay := retryDelay(response, attempt, time.Now())
+		if delay > time.Duration(transport.plugin.RetryMaxWait)*time.Second {
+			return response, nil
+		}
+		if request.Body != nil {
+			if request.GetBody == nil {
+				return response, nil
+			}
+			body, bodyErr := request.GetBody()
+			if bodyErr != nil {
+				return response, nil
+			}
+			request = request.Clone(request.Context())
+			request.Body = body
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		transport.plugin.Log.Warnf("Retrying request '%s' in %s after status %d (retry %d of %d)", request.URL, delay, response.StatusCode, attempt+1, transport.plugin.Retries)
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTripAttempt performs a single attempt bound by the configured timeout. The timeout covers reading the response
+// body, hence it is released only once the body has been closed.
+func (transport *retryingTransport) roundTripAttempt(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), time.Duration(transport.plugin.Timeout)*time.Second)
+	response, err := transport.base.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = &cancelingBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// cancelingBody releases the attempt's context once the response body is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// retryDelay determines the delay before retrying the given failed attempt.
+func retryDelay(response *http.Response, attempt int, now time.Time) time.Duration {
+	retryAfter := response.Header.Get("Retry-After")
+	if retryAfter != "" {
+		seconds, err := strconv.Atoi(retryAfter)
+		if err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		retryTime, err := http.ParseTime(retryAfter)
+		if err == nil {
+			return nonNegativeDelay(retryTime.Sub(now))
+		}
+	}
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			return nonNegativeDelay(time.Unix(reset, 0).Sub(now))
+		}
+	}
+	return retryBaseDelay << attempt
+}
+
+func nonNegativeDelay(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
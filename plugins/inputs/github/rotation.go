@@ -0,0 +1,174 @@
+// rotation.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tokenRotationRoundRobin = "round-robin"
+const tokenRotationRateLimit = "rate-limit"
+
+// tokenRate is the last known rate limit of a single access token and resource.
+type tokenRate struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// tokenRotation tracks the rate limits of the configured access tokens and selects the token to use for the next request.
+type tokenRotation struct {
+	mutex  sync.Mutex
+	active int
+	rates  map[string]map[int]*tokenRate
+}
+
+func (plugin *GitHub) checkTokenRotation() error {
+	if plugin.TokenRotation != tokenRotationRoundRobin && plugin.TokenRotation != tokenRotationRateLimit {
+		return fmt.Errorf("github: Invalid token rotation '%s'", plugin.TokenRotation)
+	}
+	if len(plugin.AccessTokens) > 0 && (!plugin.AccessToken.Empty() || !plugin.BackupAccessToken.Empty()) {
+		return errors.New("github: access_tokens cannot be combined with access_token or backup_access_token")
+	}
+	return nil
+}
+
+// exhausted reports whether the given token has no requests left for the given resource (unknown tokens and tokens whose
+// rate limit has been reset in the meantime are not exhausted).
+func (rotation *tokenRotation) exhausted(resource string, token int, now time.Time) bool {
+	rate := rotation.rates[resource][token]
+	return rate != nil && rate.remaining == 0 && now.Before(rate.reset)
+}
+
+// next selects the token to use for the next request. In round-robin mode every request uses the next token, in
+// rate-limit mode the active token is used until it is exhausted. In both modes exhausted tokens are skipped as long as
+// any other token is left.
+func (rotation *tokenRotation) next(mode string, resource string, tokens int) int {
+	rotation.mutex.Lock()
+	defer rotation.mutex.Unlock()
+	now := time.Now()
+	start := rotation.active % tokens
+	for offset := 0; offset < tokens; offset++ {
+		token := (start + offset) % tokens
+		if !rotation.exhausted(resource, token, now) {
+			start = token
+			break
+		}
+	}
+	if mode == tokenRotationRoundRobin {
+		rotation.active = (start + 1) % tokens
+	} else {
+		rotation.active = start
+	}
+	return start
+}
+
+// observe records the rate limit reported for the given token and rewrites the response's rate limit headers to the
+// budget pooled across all tokens (assuming the full limit for tokens not used so far). Thereby neither the rate limit
+// tracking nor the API client consider the rate limit used up as long as any token has requests left, and the rate
+// limit cost keeps being counted across token switches.
+func (rotation *tokenRotation) observe(resource string, token int, tokens int, response *http.Response) {
+	limit, err := strconv.Atoi(response.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(response.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, _ := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+	rotation.mutex.Lock()
+	defer rotation.mutex.Unlock()
+	if rotation.rates == nil {
+		rotation.rates = make(map[string]map[int]*tokenRate)
+	}
+	resourceRates := rotation.rates[resource]
+	if resourceRates == nil {
+		resourceRates = make(map[int]*tokenRate)
+		rotation.rates[resource] = resourceRates
+	}
+	resourceRates[token] = &tokenRate{limit: limit, remaining: remaining, reset: time.Unix(reset, 0)}
+	now := time.Now()
+	pooledLimit := 0
+	pooledRemaining := 0
+	for index := 0; index < tokens; index++ {
+		rate := resourceRates[index]
+		switch {
+		case rate == nil:
+			pooledLimit += limit
+			pooledRemaining += limit
+		case now.Before(rate.reset):
+			pooledLimit += rate.limit
+			pooledRemaining += rate.remaining
+		default:
+			pooledLimit += rate.limit
+			pooledRemaining += rate.limit
+		}
+	}
+	response.Header.Set("X-RateLimit-Limit", strconv.Itoa(pooledLimit))
+	response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(pooledRemaining))
+	response.Header.Set("X-RateLimit-Used", strconv.Itoa(pooledLimit-pooledRemaining))
+}
+
+// rotatingTransport authenticates requests with one of the configured access tokens. Requests rejected due to the
+// selected token's exhausted rate limit are retried with the next token.
+type rotatingTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *rotatingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	tokens := transport.plugin.accessTokens()
+	resource := rateLimitResource(request)
+	retryable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		token := transport.plugin.tokenRotation.next(transport.plugin.TokenRotation, resource, len(tokens))
+		authRequest := request.Clone(request.Context())
+		if attempt > 0 && request.Body != nil && request.Body != http.NoBody {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			authRequest.Body = body
+		}
+		err := authorizeRequest(authRequest, tokens[token])
+		if err != nil {
+			return nil, err
+		}
+		response, err := transport.base.RoundTrip(authRequest)
+		if err != nil {
+			return response, err
+		}
+		rateLimited := (response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusTooManyRequests) && response.Header.Get("X-RateLimit-Remaining") == "0"
+		transport.plugin.tokenRotation.observe(resource, token, len(tokens), response)
+		if !rateLimited || attempt >= len(tokens)-1 || !retryable {
+			return response, nil
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		transport.plugin.Log.Warnf("Rate limit of access token %d exhausted; rotating to the next access token", token+1)
+	}
+}
+
+// rateLimitResource derives the rate limit resource a request is accounted to from its path.
+func rateLimitResource(request *http.Request) string {
+	if strings.HasSuffix(request.URL.Path, "/graphql") {
+		return "graphql"
+	}
+	if strings.Contains(request.URL.Path+"/", "/search/") {
+		return "search"
+	}
+	return "core"
+}
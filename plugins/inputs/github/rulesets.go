@@ -0,0 +1,120 @@
+// rulesets.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// ruleset represents a repository or organization ruleset (not yet covered by the go-github API version in use).
+type ruleset struct {
+	ID           int64                 `json:"id"`
+	Name         string                `json:"name"`
+	Target       string                `json:"target"`
+	Enforcement  string                `json:"enforcement"`
+	BypassActors []*rulesetBypassActor `json:"bypass_actors"`
+	Conditions   *rulesetConditions    `json:"conditions"`
+}
+
+type rulesetConditions struct {
+	RefName *struct {
+		Include []string `json:"include"`
+		Exclude []string `json:"exclude"`
+	} `json:"ref_name"`
+}
+
+type rulesetBypassActor struct {
+	ActorID    int64  `json:"actor_id"`
+	ActorType  string `json:"actor_type"`
+	BypassMode string `json:"bypass_mode"`
+}
+
+func (plugin *GitHub) processRepoRulesets(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	// org rulesets are reported once per org (if requested) instead of once per repo
+	return plugin.processRulesets(ctx, client, a, tags, fmt.Sprintf("repos/%s/%s/rulesets", repoOwner, repoName), "includes_parents=false&")
+}
+
+func (plugin *GitHub) processOrgRulesets(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	return plugin.processRulesets(ctx, client, a, tags, fmt.Sprintf("orgs/%s/rulesets", org), "")
+}
+
+// processRulesets reports the bypass actors per ruleset. As the ruleset list does not contain the bypass actors, every
+// ruleset is fetched individually.
+func (plugin *GitHub) processRulesets(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, baseTags map[string]string, rulesetsURL string, listQuery string) error {
+	rulesets, err := plugin.listRulesets(ctx, client, rulesetsURL, listQuery)
+	if err != nil {
+		return err
+	}
+	for _, listedRuleset := range rulesets {
+		fetchedRuleset, err := plugin.getRuleset(ctx, client, rulesetsURL, listedRuleset.ID)
+		if err != nil {
+			return err
+		}
+		pullRequestBypassActors := 0
+		for _, bypassActor := range fetchedRuleset.BypassActors {
+			if bypassActor.BypassMode == "pull_request" {
+				pullRequestBypassActors++
+			}
+		}
+		tags := make(map[string]string)
+		for key, value := range baseTags {
+			tags[key] = value
+		}
+		tags["ruleset"] = fetchedRuleset.Name
+		tags["target"] = fetchedRuleset.Target
+		tags["enforcement"] = fetchedRuleset.Enforcement
+		fields := make(map[string]interface{})
+		fields["bypass_actors"] = len(fetchedRuleset.BypassActors)
+		fields["always_bypass_actors"] = len(fetchedRuleset.BypassActors) - pullRequestBypassActors
+		fields["pull_request_bypass_actors"] = pullRequestBypassActors
+		a.AddCounter("github_ruleset", fields, tags)
+	}
+	return nil
+}
+
+// listRulesets lists all rulesets (without bypass actors and conditions) of the given rulesets URL.
+func (plugin *GitHub) listRulesets(ctx context.Context, client *githubApi.Client, rulesetsURL string, listQuery string) ([]*ruleset, error) {
+	rulesets := make([]*ruleset, 0)
+	for page := 1; ; page++ {
+		request, err := client.NewRequest("GET", fmt.Sprintf("%s?%sper_page=100&page=%d", rulesetsURL, listQuery, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		var pageRulesets []*ruleset
+		response, err := client.Do(ctx, request, &pageRulesets)
+		if err != nil {
+			return nil, err
+		}
+		rulesets = append(rulesets, pageRulesets...)
+		if response.NextPage == 0 {
+			break
+		}
+	}
+	return rulesets, nil
+}
+
+func (plugin *GitHub) getRuleset(ctx context.Context, client *githubApi.Client, rulesetsURL string, id int64) (*ruleset, error) {
+	request, err := client.NewRequest("GET", fmt.Sprintf("%s/%d", rulesetsURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	fetchedRuleset := &ruleset{}
+	_, err = client.Do(ctx, request, fetchedRuleset)
+	if err != nil {
+		return nil, err
+	}
+	return fetchedRuleset, nil
+}
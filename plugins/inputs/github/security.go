@@ -0,0 +1,148 @@
+// security.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const ipAllowListQuery = `query($org: String!) {
+  organization(login: $org) {
+    ipAllowListEnabledSetting
+    ipAllowListEntries(first: 100) {
+      totalCount
+      nodes {
+        isActive
+      }
+    }
+  }
+}`
+
+type ipAllowListResult struct {
+	Organization struct {
+		IPAllowListEnabledSetting string `json:"ipAllowListEnabledSetting"`
+		IPAllowListEntries        struct {
+			TotalCount int `json:"totalCount"`
+			Nodes      []struct {
+				IsActive bool `json:"isActive"`
+			} `json:"nodes"`
+		} `json:"ipAllowListEntries"`
+	} `json:"organization"`
+}
+
+func (plugin *GitHub) processIPAllowList(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	result := &ipAllowListResult{}
+	err := plugin.queryGraphQL(ctx, client, ipAllowListQuery, map[string]interface{}{"org": org}, result)
+	if err != nil {
+		return err
+	}
+	activeEntries := 0
+	for _, entry := range result.Organization.IPAllowListEntries.Nodes {
+		if entry.IsActive {
+			activeEntries++
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	fields := make(map[string]interface{})
+	fields["enabled"] = result.Organization.IPAllowListEnabledSetting == "ENABLED"
+	fields["entries"] = result.Organization.IPAllowListEntries.TotalCount
+	fields["active_entries"] = activeEntries
+	a.AddCounter("github_ip_allow_list", fields, tags)
+	return nil
+}
+
+// credentialAuthorization represents an SSO credential authorization (not yet covered by the go-github API version in use).
+type credentialAuthorization struct {
+	Login          string `json:"login"`
+	CredentialID   int64  `json:"credential_id"`
+	CredentialType string `json:"credential_type"`
+}
+
+func (plugin *GitHub) processCredentialAuthorizations(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	typeCounts := make(map[string]int)
+	typeLogins := make(map[string]map[string]bool)
+	page := 1
+	for page != 0 {
+		request, err := client.NewRequest("GET", fmt.Sprintf("orgs/%s/credential-authorizations?per_page=100&page=%d", org, page), nil)
+		if err != nil {
+			return err
+		}
+		var credentialAuthorizations []*credentialAuthorization
+		response, err := client.Do(ctx, request, &credentialAuthorizations)
+		if err != nil {
+			return err
+		}
+		for _, credentialAuthorization := range credentialAuthorizations {
+			typeCounts[credentialAuthorization.CredentialType]++
+			logins := typeLogins[credentialAuthorization.CredentialType]
+			if logins == nil {
+				logins = make(map[string]bool)
+				typeLogins[credentialAuthorization.CredentialType] = logins
+			}
+			logins[credentialAuthorization.Login] = true
+		}
+		page = response.NextPage
+	}
+	for credentialType, count := range typeCounts {
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["credential_type"] = credentialType
+		fields := make(map[string]interface{})
+		fields["credentials"] = count
+		fields["users"] = len(typeLogins[credentialType])
+		a.AddCounter("github_credential_authorizations", fields, tags)
+	}
+	return nil
+}
+
+// patRequest represents a pending fine-grained personal access token request (not yet covered by the go-github API version in use).
+type patRequest struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (plugin *GitHub) processPATRequests(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	pendingRequests := 0
+	oldestRequest := time.Time{}
+	page := 1
+	for page != 0 {
+		request, err := client.NewRequest("GET", fmt.Sprintf("orgs/%s/personal-access-token-requests?per_page=100&page=%d", org, page), nil)
+		if err != nil {
+			return err
+		}
+		var patRequests []*patRequest
+		response, err := client.Do(ctx, request, &patRequests)
+		if err != nil {
+			return err
+		}
+		for _, patRequest := range patRequests {
+			pendingRequests++
+			if oldestRequest.IsZero() || patRequest.CreatedAt.Before(oldestRequest) {
+				oldestRequest = patRequest.CreatedAt
+			}
+		}
+		page = response.NextPage
+	}
+	var oldestRequestAge int64
+	if !oldestRequest.IsZero() {
+		oldestRequestAge = int64(time.Since(oldestRequest).Seconds())
+	}
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	fields := make(map[string]interface{})
+	fields["pending_requests"] = pendingRequests
+	fields["oldest_request_age_seconds"] = oldestRequestAge
+	a.AddCounter("github_pat_requests", fields, tags)
+	return nil
+}
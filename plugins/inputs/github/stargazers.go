@@ -0,0 +1,108 @@
+// stargazers.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+const collectorStargazerLocations = "stargazer_locations"
+
+const stargazerLocationOther = "<other>"
+const stargazerLocationUnknown = "<unknown>"
+
+const stargazerLocationsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    stargazers(first: 100, after: $cursor, orderBy: {field: STARRED_AT, direction: DESC}) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+      nodes {
+        location
+      }
+    }
+  }
+}`
+
+type stargazerLocationsResult struct {
+	Repository struct {
+		Stargazers struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Location string `json:"location"`
+			} `json:"nodes"`
+		} `json:"stargazers"`
+	} `json:"repository"`
+}
+
+// processStargazerLocations samples the most recent stargazers and counts them per location bucket. The free-text
+// profile location is matched against the configured keywords; stargazers without location or without matching keyword
+// are counted as unknown resp. other. The result is a rough estimate only, therefore the sample is taken at a low
+// frequency (see stargazer_locations_interval).
+func (plugin *GitHub) processStargazerLocations(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	buckets := make(map[string]int)
+	sampled := 0
+	variables := map[string]interface{}{"owner": repoOwner, "name": repoName}
+	for page := 0; page < plugin.StargazerSamplePages; page++ {
+		result := &stargazerLocationsResult{}
+		err := plugin.queryGraphQL(ctx, client, stargazerLocationsQuery, variables, result)
+		if err != nil {
+			return err
+		}
+		for _, stargazer := range result.Repository.Stargazers.Nodes {
+			sampled++
+			buckets[plugin.stargazerLocationBucket(stargazer.Location)]++
+		}
+		if !result.Repository.Stargazers.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = result.Repository.Stargazers.PageInfo.EndCursor
+	}
+	for bucket, stargazers := range buckets {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["location"] = bucket
+		fields := make(map[string]interface{})
+		fields["stargazers"] = stargazers
+		fields["sampled_stargazers"] = sampled
+		fields["share_percent"] = float64(stargazers) * 100.0 / float64(sampled)
+		a.AddCounter("github_stargazer_locations", fields, tags)
+	}
+	return nil
+}
+
+// stargazerLocationBucket maps a profile location to the first bucket (in bucket name order) with a keyword contained in
+// the location (ignoring case).
+func (plugin *GitHub) stargazerLocationBucket(location string) string {
+	location = strings.ToLower(strings.TrimSpace(location))
+	if location == "" {
+		return stargazerLocationUnknown
+	}
+	buckets := make([]string, 0, len(plugin.StargazerLocationKeywords))
+	for bucket := range plugin.StargazerLocationKeywords {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	for _, bucket := range buckets {
+		for _, keyword := range plugin.StargazerLocationKeywords[bucket] {
+			if keyword != "" && strings.Contains(location, strings.ToLower(keyword)) {
+				return bucket
+			}
+		}
+	}
+	return stargazerLocationOther
+}
@@ -0,0 +1,157 @@
+// state.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+)
+
+// repoState holds the repository attributes tracked between gathers to detect changes.
+type repoState struct {
+	Visibility         string
+	Owner              string
+	DefaultBranch      string
+	Size               int
+	StarSamples        []starSample
+	LastViewTimestamp  time.Time
+	LastCloneTimestamp time.Time
+	// restored marks states restored from a previous run, whose last traffic entries have already been emitted
+	restored bool
+}
+
+// pluginState is the plugin state persisted by Telegraf (respectively in the configured state file) across restarts.
+type pluginState struct {
+	Repos          map[string]*repoState
+	ReleaseDigests map[string]string
+}
+
+func (plugin *GitHub) GetState() interface{} {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	return pluginState{
+		Repos:          plugin.repoStates,
+		ReleaseDigests: plugin.releaseDigests,
+	}
+}
+
+func (plugin *GitHub) SetState(state interface{}) error {
+	restored, ok := state.(pluginState)
+	if !ok {
+		return fmt.Errorf("github: Invalid state type %T", state)
+	}
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	if restored.Repos != nil {
+		for _, state := range restored.Repos {
+			state.restored = true
+		}
+		plugin.repoStates = restored.Repos
+	}
+	if restored.ReleaseDigests != nil {
+		plugin.releaseDigests = restored.ReleaseDigests
+	}
+	return nil
+}
+
+// loadStateFile restores the plugin state from the configured state file (if any). As the execd shim does not persist
+// the plugin state via GetState/SetState, the state file is the only way to keep it across restarts there.
+func (plugin *GitHub) loadStateFile() error {
+	if plugin.StateFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(plugin.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("github: Failed to read state file '%s': %v", plugin.StateFile, err)
+	}
+	var state pluginState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		plugin.Log.Warnf("Ignoring invalid state file '%s': %v", plugin.StateFile, err)
+		return nil
+	}
+	return plugin.SetState(state)
+}
+
+// saveStateFile persists the plugin state in the configured state file (if any) via a temporary file, so an interrupted
+// write never leaves a partially written state file behind.
+func (plugin *GitHub) saveStateFile() {
+	if plugin.StateFile == "" {
+		return
+	}
+	plugin.stateMutex.Lock()
+	data, err := json.Marshal(pluginState{Repos: plugin.repoStates, ReleaseDigests: plugin.releaseDigests})
+	plugin.stateMutex.Unlock()
+	if err == nil {
+		var tempFile *os.File
+		tempFile, err = os.CreateTemp(filepath.Dir(plugin.StateFile), "*.tmp")
+		if err == nil {
+			_, err = tempFile.Write(data)
+			closeErr := tempFile.Close()
+			if err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				err = os.Rename(tempFile.Name(), plugin.StateFile)
+			}
+			if err != nil {
+				os.Remove(tempFile.Name())
+			}
+		}
+	}
+	if err != nil {
+		plugin.Log.Warnf("Failed to write state file '%s': %v", plugin.StateFile, err)
+	}
+}
+
+func (plugin *GitHub) getRepoState(repo string) (*repoState, bool) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	state, known := plugin.repoStates[repo]
+	if !known {
+		state = &repoState{}
+		plugin.repoStates[repo] = state
+	}
+	return state, known
+}
+
+func (plugin *GitHub) updateRepoState(state *repoState, repoInfo *githubApi.Repository) {
+	state.Visibility = repoVisibility(repoInfo)
+	state.Owner = repoInfo.GetOwner().GetLogin()
+	state.DefaultBranch = repoInfo.GetDefaultBranch()
+	state.Size = repoInfo.GetSize()
+}
+
+func repoVisibility(repoInfo *githubApi.Repository) string {
+	visibility := repoInfo.GetVisibility()
+	if visibility == "" {
+		visibility = "public"
+		if repoInfo.GetPrivate() {
+			visibility = "private"
+		}
+	}
+	return visibility
+}
+
+// swapReleaseDigest records the release's current assets digest and returns the previously recorded one (if any).
+func (plugin *GitHub) swapReleaseDigest(digestKey string, digest string) (string, bool) {
+	plugin.stateMutex.Lock()
+	defer plugin.stateMutex.Unlock()
+	previousDigest, known := plugin.releaseDigests[digestKey]
+	plugin.releaseDigests[digestKey] = digest
+	return previousDigest, known
+}
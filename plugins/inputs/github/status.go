@@ -0,0 +1,88 @@
+// status.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/telegraf"
+)
+
+const defaultStatusPageURL = "https://www.githubstatus.com/api/v2/components.json"
+
+// statusLevels maps the status page component states to a numeric severity.
+var statusLevels = map[string]int{
+	"operational":          0,
+	"under_maintenance":    1,
+	"degraded_performance": 2,
+	"partial_outage":       3,
+	"major_outage":         4,
+}
+
+type statusPageComponents struct {
+	Components []*statusPageComponent `json:"components"`
+}
+
+type statusPageComponent struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Group  bool   `json:"group"`
+}
+
+func (plugin *GitHub) processStatusPage(ctx context.Context, a telegraf.Accumulator) error {
+	components, err := plugin.getStatusPageComponents(ctx)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool)
+	for _, name := range plugin.StatusComponents {
+		selected[name] = true
+	}
+	for _, component := range components.Components {
+		if component.Group || (len(selected) > 0 && !selected[component.Name]) {
+			continue
+		}
+		level, known := statusLevels[component.Status]
+		if !known {
+			level = -1
+		}
+		tags := make(map[string]string)
+		tags["component"] = component.Name
+		fields := make(map[string]interface{})
+		fields["status"] = component.Status
+		fields["status_level"] = level
+		fields["operational"] = component.Status == "operational"
+		a.AddFields("github_status", fields, tags)
+	}
+	return nil
+}
+
+func (plugin *GitHub) getStatusPageComponents(ctx context.Context) (*statusPageComponents, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, plugin.StatusPageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := plugin.externalClient
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: Status page request failed with status %d", response.StatusCode)
+	}
+	components := &statusPageComponents{}
+	err = json.NewDecoder(response.Body).Decode(components)
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
@@ -0,0 +1,106 @@
+// statuses.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"sort"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// statusContext aggregates the occurrences of a single status context (commit status or check run) across the
+// inspected commits.
+type statusContext struct {
+	commits  int
+	onLatest bool
+	state    string
+}
+
+// processStatusContexts inventories the status contexts (commit statuses as well as check runs) reported for the recent
+// commits of the default branch. Contexts seen on earlier commits but missing on the latest one indicate an integration
+// which stopped reporting (e.g. a removed or broken CI job).
+func (plugin *GitHub) processStatusContexts(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, repoInfo *githubApi.Repository) error {
+	opts := &githubApi.CommitsListOptions{SHA: repoInfo.GetDefaultBranch(), ListOptions: githubApi.ListOptions{PerPage: plugin.StatusContextCommits}}
+	commits, _, err := client.Repositories.ListCommits(ctx, repoOwner, repoName, opts)
+	if err != nil {
+		return err
+	}
+	contexts := make(map[string]*statusContext)
+	for i, commit := range commits {
+		commitContexts, err := plugin.listCommitStatusContexts(ctx, client, repoOwner, repoName, commit.GetSHA())
+		if err != nil {
+			return err
+		}
+		for name, state := range commitContexts {
+			entry := contexts[name]
+			if entry == nil {
+				// commits are listed newest first, hence the first occurrence holds the most recent state
+				entry = &statusContext{onLatest: i == 0, state: state}
+				contexts[name] = entry
+			}
+			entry.commits++
+		}
+	}
+	names := make([]string, 0, len(contexts))
+	missingContexts := 0
+	for name, entry := range contexts {
+		names = append(names, name)
+		if !entry.onLatest {
+			missingContexts++
+		}
+	}
+	sort.Strings(names)
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["commits"] = len(commits)
+	fields["contexts"] = len(contexts)
+	fields["missing_contexts"] = missingContexts
+	a.AddCounter("github_status_contexts", fields, tags)
+	for i, name := range names {
+		if plugin.MaxStatusContexts > 0 && i >= plugin.MaxStatusContexts {
+			break
+		}
+		entry := contexts[name]
+		contextTags := make(map[string]string)
+		contextTags["github_repo"] = repo
+		contextTags["context"] = name
+		contextFields := make(map[string]interface{})
+		contextFields["commits"] = entry.commits
+		contextFields["on_latest_commit"] = entry.onLatest
+		contextFields["state"] = entry.state
+		a.AddCounter("github_status_context", contextFields, contextTags)
+	}
+	return nil
+}
+
+// listCommitStatusContexts gets the state (resp. conclusion) of all status contexts and check runs of a commit.
+func (plugin *GitHub) listCommitStatusContexts(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, sha string) (map[string]string, error) {
+	contexts := make(map[string]string)
+	combinedStatus, _, err := client.Repositories.GetCombinedStatus(ctx, repoOwner, repoName, sha, &githubApi.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range combinedStatus.Statuses {
+		contexts[status.GetContext()] = status.GetState()
+	}
+	checkRuns, _, err := client.Checks.ListCheckRunsForRef(ctx, repoOwner, repoName, sha, &githubApi.ListCheckRunsOptions{ListOptions: githubApi.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, err
+	}
+	for _, checkRun := range checkRuns.CheckRuns {
+		state := checkRun.GetConclusion()
+		if state == "" {
+			state = checkRun.GetStatus()
+		}
+		contexts[checkRun.GetName()] = state
+	}
+	return contexts, nil
+}
@@ -0,0 +1,146 @@
+// submodules.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+type submodule struct {
+	path string
+	url  string
+}
+
+var submoduleURLPattern = regexp.MustCompile(`^(?:https?://|git@|ssh://git@)([^/:]+)(?::[0-9]+)?[/:]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// submoduleHosts determines the hosts submodule URLs are resolved for (github.com as well as the host of the configured
+// API base URL).
+func (plugin *GitHub) submoduleHosts() []string {
+	hosts := []string{"github.com"}
+	if plugin.APIBaseURL != "" {
+		apiBaseURL, err := url.Parse(plugin.APIBaseURL)
+		if err == nil && apiBaseURL.Hostname() != "" {
+			hosts = append(hosts, strings.TrimPrefix(apiBaseURL.Hostname(), "api."))
+		}
+	}
+	return hosts
+}
+
+func (plugin *GitHub) processSubmodules(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	gitmodulesFile, _, response, err := client.Repositories.GetContents(ctx, repoOwner, repoName, ".gitmodules", nil)
+	if err != nil {
+		// repositories without submodules have no .gitmodules file
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	if gitmodulesFile == nil {
+		return nil
+	}
+	gitmodules, err := gitmodulesFile.GetContent()
+	if err != nil {
+		return err
+	}
+	hosts := plugin.submoduleHosts()
+	for _, submodule := range parseGitmodules(gitmodules) {
+		upstreamOwner, upstreamName, ok := submoduleUpstream(submodule.url, repoOwner, hosts)
+		if !ok {
+			if plugin.Debug {
+				plugin.Log.Infof("Ignoring submodule '%s' with non-GitHub URL '%s'", submodule.path, submodule.url)
+			}
+			continue
+		}
+		pinned, _, _, err := client.Repositories.GetContents(ctx, repoOwner, repoName, submodule.path, nil)
+		if err != nil {
+			return err
+		}
+		if pinned == nil || pinned.GetType() != "submodule" {
+			continue
+		}
+		upstreamInfo, _, err := client.Repositories.Get(ctx, upstreamOwner, upstreamName)
+		if err != nil {
+			return err
+		}
+		comparison, _, err := client.Repositories.CompareCommits(ctx, upstreamOwner, upstreamName, pinned.GetSHA(), upstreamInfo.GetDefaultBranch(), nil)
+		if err != nil {
+			return err
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["submodule"] = submodule.path
+		fields := make(map[string]interface{})
+		fields["upstream"] = upstreamOwner + "/" + upstreamName
+		fields["pinned_sha"] = pinned.GetSHA()
+		fields["commits_behind"] = comparison.GetAheadBy()
+		fields["stale"] = comparison.GetAheadBy() > 0
+		a.AddCounter("github_submodule", fields, tags)
+	}
+	return nil
+}
+
+func parseGitmodules(gitmodules string) []*submodule {
+	submodules := make([]*submodule, 0)
+	var current *submodule
+	scanner := bufio.NewScanner(strings.NewReader(gitmodules))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			current = &submodule{}
+			submodules = append(submodules, current)
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || current == nil {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "path":
+			current.path = strings.TrimSpace(value)
+		case "url":
+			current.url = strings.TrimSpace(value)
+		}
+	}
+	validSubmodules := make([]*submodule, 0, len(submodules))
+	for _, submodule := range submodules {
+		if submodule.path != "" && submodule.url != "" {
+			validSubmodules = append(validSubmodules, submodule)
+		}
+	}
+	return validSubmodules
+}
+
+// submoduleUpstream derives the upstream repository from the submodule URL (relative URLs refer to the same owner).
+// Absolute URLs are only resolved if they refer to one of the given hosts.
+func submoduleUpstream(url string, repoOwner string, hosts []string) (string, string, bool) {
+	if strings.HasPrefix(url, "../") {
+		name := strings.TrimSuffix(strings.TrimPrefix(url, "../"), ".git")
+		if name == "" || strings.Contains(name, "/") {
+			return "", "", false
+		}
+		return repoOwner, name, true
+	}
+	match := submoduleURLPattern.FindStringSubmatch(url)
+	if match == nil {
+		return "", "", false
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(match[1], host) {
+			return match[2], match[3], true
+		}
+	}
+	return "", "", false
+}
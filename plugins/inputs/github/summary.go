@@ -0,0 +1,113 @@
+// summary.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gatherSummary collects the figures of a single gather logged as one structured (JSON) summary line.
+type gatherSummary struct {
+	mutex          sync.Mutex
+	start          time.Time
+	calls          int
+	reposProcessed int
+	reposFailed    int
+	errors         map[string]int
+}
+
+type gatherSummaryLine struct {
+	Summary        string         `json:"summary"`
+	DurationMillis int64          `json:"duration_ms"`
+	Repos          int            `json:"repos"`
+	ReposProcessed int            `json:"repos_processed"`
+	ReposFailed    int            `json:"repos_failed"`
+	ReposSkipped   int            `json:"repos_skipped"`
+	Calls          int            `json:"calls"`
+	RateLimitCost  int            `json:"rate_limit_cost"`
+	Errors         map[string]int `json:"errors"`
+}
+
+func newGatherSummary(start time.Time) *gatherSummary {
+	return &gatherSummary{start: start, errors: make(map[string]int)}
+}
+
+// recordCall counts an API call and (if it failed) its failure per endpoint.
+func (summary *gatherSummary) recordCall(request *http.Request, response *http.Response, err error) {
+	summary.mutex.Lock()
+	defer summary.mutex.Unlock()
+	summary.calls++
+	if err != nil || response.StatusCode >= http.StatusBadRequest {
+		summary.errors[summaryEndpoint(request)]++
+	}
+}
+
+func (summary *gatherSummary) recordRepo(err error) {
+	summary.mutex.Lock()
+	defer summary.mutex.Unlock()
+	summary.reposProcessed++
+	if err != nil {
+		summary.reposFailed++
+	}
+}
+
+// recordRepoSummary counts a processed repo (if the gather summary is enabled).
+func (plugin *GitHub) recordRepoSummary(err error) {
+	if plugin.gatherSummary != nil {
+		plugin.gatherSummary.recordRepo(err)
+	}
+}
+
+// line renders the summary as a single JSON line.
+func (summary *gatherSummary) line(repos int, reposSkipped int, rateLimitCost int) string {
+	summary.mutex.Lock()
+	defer summary.mutex.Unlock()
+	line := &gatherSummaryLine{
+		Summary:        "gather",
+		DurationMillis: time.Since(summary.start).Milliseconds(),
+		Repos:          repos,
+		ReposProcessed: summary.reposProcessed,
+		ReposFailed:    summary.reposFailed,
+		ReposSkipped:   reposSkipped,
+		Calls:          summary.calls,
+		RateLimitCost:  rateLimitCost,
+		Errors:         summary.errors,
+	}
+	encoded, _ := json.Marshal(line)
+	return string(encoded)
+}
+
+var summaryEndpointIDPattern = regexp.MustCompile(`^[0-9]+$|^[0-9a-f]{40}$`)
+
+// summaryEndpoint derives the endpoint a request is accounted to (replacing the owner, repo, org and user names as well
+// as numeric ids and commit hashes by placeholders to keep the number of reported endpoints bounded).
+func summaryEndpoint(request *http.Request) string {
+	// strip the GitHub Enterprise API prefixes (/api/v3 respectively /api for GraphQL)
+	path := strings.TrimPrefix(strings.TrimPrefix(request.URL.Path, "/api/v3/"), "/api/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for index, segment := range segments {
+		switch {
+		case index == 1 && (segments[0] == "orgs" || segments[0] == "organizations"):
+			segments[index] = ":org"
+		case index == 1 && segments[0] == "users":
+			segments[index] = ":user"
+		case index == 1 && segments[0] == "repos":
+			segments[index] = ":owner"
+		case index == 2 && segments[0] == "repos":
+			segments[index] = ":repo"
+		case summaryEndpointIDPattern.MatchString(segment):
+			segments[index] = ":id"
+		}
+	}
+	return request.Method + " /" + strings.Join(segments, "/")
+}
@@ -0,0 +1,91 @@
+// tags.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// processTagProtection reports the tag rulesets (which replaced the retired tag protection rules) applying to the repo
+// (including the rulesets inherited from the org). Only actively enforced rulesets count towards the enabled state and
+// the protected tag patterns.
+func (plugin *GitHub) processTagProtection(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	rulesetsURL := fmt.Sprintf("repos/%s/%s/rulesets", repoOwner, repoName)
+	rulesets, err := plugin.listRulesets(ctx, client, rulesetsURL, "includes_parents=true&")
+	if err != nil {
+		return err
+	}
+	tagRulesets := 0
+	activeTagRulesets := 0
+	patterns := 0
+	for _, listedRuleset := range rulesets {
+		if listedRuleset.Target != "tag" {
+			continue
+		}
+		tagRulesets++
+		if listedRuleset.Enforcement != "active" {
+			continue
+		}
+		fetchedRuleset, err := plugin.getRuleset(ctx, client, rulesetsURL, listedRuleset.ID)
+		if err != nil {
+			return err
+		}
+		activeTagRulesets++
+		if fetchedRuleset.Conditions != nil && fetchedRuleset.Conditions.RefName != nil {
+			patterns += len(fetchedRuleset.Conditions.RefName.Include)
+		}
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["enabled"] = activeTagRulesets > 0
+	fields["rulesets"] = tagRulesets
+	fields["active_rulesets"] = activeTagRulesets
+	fields["patterns"] = patterns
+	a.AddCounter("github_tag_protection", fields, tags)
+	return nil
+}
+
+// processTagCoverage counts the repo's tags lacking a corresponding release. Releases beyond the configured release page
+// limit are not known and their tags are therefore counted as lacking a release.
+func (plugin *GitHub) processTagCoverage(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, repoReleases []*githubApi.RepositoryRelease) error {
+	releaseTags := make(map[string]bool)
+	for _, repoRelease := range repoReleases {
+		releaseTags[repoRelease.GetTagName()] = true
+	}
+	tagCount := 0
+	tagsWithoutRelease := 0
+	opts := &githubApi.ListOptions{PerPage: 100}
+	for {
+		repoTags, response, err := client.Repositories.ListTags(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return err
+		}
+		for _, repoTag := range repoTags {
+			tagCount++
+			if !releaseTags[repoTag.GetName()] {
+				tagsWithoutRelease++
+			}
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	tags := make(map[string]string)
+	tags["github_repo"] = repo
+	fields := make(map[string]interface{})
+	fields["tags"] = tagCount
+	fields["tags_without_release"] = tagsWithoutRelease
+	a.AddCounter("github_tag_coverage", fields, tags)
+	return nil
+}
@@ -0,0 +1,85 @@
+// tokenfile.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFile holds the access token last read from the access token file together with the file state it was read at.
+type tokenFile struct {
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func (plugin *GitHub) checkAccessTokenFile() error {
+	if plugin.AccessTokenFile != "" && (!plugin.AccessToken.Empty() || !plugin.BackupAccessToken.Empty() || len(plugin.AccessTokens) > 0) {
+		return errors.New("github: access_token_file cannot be combined with other access tokens")
+	}
+	return nil
+}
+
+// currentFileToken gets the access token from the access token file, re-reading the file whenever it has been changed
+// since the last read. If the changed file cannot be read (e.g. because it is just being rewritten), the previously read
+// token is used.
+func (plugin *GitHub) currentFileToken() (string, error) {
+	state := &plugin.tokenFile
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	fileInfo, err := os.Stat(plugin.AccessTokenFile)
+	if err == nil && state.token != "" && fileInfo.ModTime().Equal(state.modTime) && fileInfo.Size() == state.size {
+		return state.token, nil
+	}
+	var token string
+	if err == nil {
+		var data []byte
+		data, err = os.ReadFile(plugin.AccessTokenFile)
+		token = strings.TrimSpace(string(data))
+		if err == nil && token == "" {
+			err = fmt.Errorf("github: Empty access token file '%s'", plugin.AccessTokenFile)
+		}
+	}
+	if err != nil {
+		if state.token == "" {
+			return "", err
+		}
+		plugin.Log.Warnf("Keeping previous access token as access token file '%s' cannot be read: %v", plugin.AccessTokenFile, err)
+		return state.token, nil
+	}
+	if state.token != "" && plugin.Debug {
+		plugin.Log.Debugf("Reloaded access token from access token file '%s'...", plugin.AccessTokenFile)
+	}
+	state.modTime = fileInfo.ModTime()
+	state.size = fileInfo.Size()
+	state.token = token
+	return token, nil
+}
+
+// fileTokenTransport authenticates requests with the access token read from the access token file.
+type fileTokenTransport struct {
+	base   http.RoundTripper
+	plugin *GitHub
+}
+
+func (transport *fileTokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := transport.plugin.currentFileToken()
+	if err != nil {
+		return nil, err
+	}
+	authRequest := request.Clone(request.Context())
+	authRequest.Header.Set("Authorization", "Bearer "+token)
+	return transport.base.RoundTrip(authRequest)
+}
@@ -0,0 +1,195 @@
+// traffic.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+// starSampleWindow matches the period covered by the traffic API.
+const starSampleWindow = 14 * 24 * time.Hour
+
+type starSample struct {
+	Time  time.Time
+	Stars int
+}
+
+// starsPerUniqueView records the current star count and computes the number of new stars per unique view since the
+// oldest star sample still within the traffic window. Matching the traffic API's granularity, only the first star count
+// of every (UTC) day is recorded.
+func (plugin *GitHub) starsPerUniqueView(state *repoState, stars int, views []*githubApi.TrafficData, now time.Time) float64 {
+	today := now.UTC().Truncate(24 * time.Hour)
+	if len(state.StarSamples) == 0 || state.StarSamples[len(state.StarSamples)-1].Time.Before(today) {
+		state.StarSamples = append(state.StarSamples, starSample{Time: now, Stars: stars})
+	}
+	windowStart := now.Add(-starSampleWindow)
+	for len(state.StarSamples) > 1 && !state.StarSamples[1].Time.After(windowStart) {
+		state.StarSamples = state.StarSamples[1:]
+	}
+	reference := state.StarSamples[0]
+	referenceDay := reference.Time.UTC().Truncate(24 * time.Hour)
+	newStars := stars - reference.Stars
+	uniques := 0
+	for _, view := range views {
+		if !view.GetTimestamp().Before(referenceDay) {
+			uniques += view.GetUniques()
+		}
+	}
+	if newStars <= 0 || uniques == 0 {
+		return 0.0
+	}
+	return float64(newStars) / float64(uniques)
+}
+
+// processTraffic gathers the latest traffic views and clones (adding them to the given repo info fields). Without
+// authentication (or on Gitea) the traffic API is not available and the traffic stats are reported as zero.
+func (plugin *GitHub) processTraffic(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string, repoInfo *githubApi.Repository, state *repoState, fields map[string]interface{}) error {
+	viewTimestamp := time.Time{}
+	var totalViews int
+	var uniqueViews int
+	cloneTimestamp := time.Time{}
+	var totalClones int
+	var uniqueClones int
+
+	if plugin.authenticated() && !plugin.isGitea() {
+		repoTrafficViews, _, err := client.Repositories.ListTrafficViews(ctx, repoOwner, repoName, &githubApi.TrafficBreakdownOptions{Per: plugin.TrafficBreakdown})
+		if err != nil {
+			return err
+		}
+		for _, repoTrafficView := range repoTrafficViews.Views {
+			if repoTrafficView.Timestamp.After(viewTimestamp) {
+				viewTimestamp = repoTrafficView.Timestamp.Time
+				totalViews = repoTrafficView.GetCount()
+				uniqueViews = repoTrafficView.GetUniques()
+			}
+		}
+		fields["stars_per_unique_view"] = plugin.starsPerUniqueView(state, repoInfo.GetStargazersCount(), repoTrafficViews.Views, time.Now())
+		if plugin.TrafficSeries {
+			plugin.processTrafficSeries(a, repo, "github_traffic_views", repoTrafficViews.Views, &state.LastViewTimestamp, state.restored)
+		}
+		if plugin.TrafficExport {
+			plugin.processTrafficExport(a, repo, "views", repoTrafficViews.Views)
+		}
+		repoTrafficClones, _, err := client.Repositories.ListTrafficClones(ctx, repoOwner, repoName, &githubApi.TrafficBreakdownOptions{Per: plugin.TrafficBreakdown})
+		if err != nil {
+			return err
+		}
+		for _, repoTrafficClone := range repoTrafficClones.Clones {
+			if repoTrafficClone.Timestamp.After(cloneTimestamp) {
+				cloneTimestamp = repoTrafficClone.Timestamp.Time
+				totalClones = repoTrafficClone.GetCount()
+				uniqueClones = repoTrafficClone.GetUniques()
+			}
+		}
+		if plugin.TrafficSeries {
+			plugin.processTrafficSeries(a, repo, "github_traffic_clones", repoTrafficClones.Clones, &state.LastCloneTimestamp, state.restored)
+			state.restored = false
+		}
+		if plugin.TrafficExport {
+			plugin.processTrafficExport(a, repo, "clones", repoTrafficClones.Clones)
+		}
+		// lets dashboards tell missing traffic history (young repos) apart from zero traffic
+		fields["created_at"] = repoInfo.GetCreatedAt().Unix()
+		fields["traffic_days_available"] = trafficDaysAvailable(repoInfo.GetCreatedAt().Time, time.Now())
+	}
+	fields["total_views"] = totalViews
+	fields["unique_views"] = uniqueViews
+	fields["total_clones"] = totalClones
+	fields["unique_clones"] = uniqueClones
+	return nil
+}
+
+// trafficDaysAvailable gets the number of days (including the current one) covered by the repo's traffic history. It is
+// below the traffic window for repos younger than the window, whose traffic entries are therefore incomplete or missing.
+func trafficDaysAvailable(createdAt time.Time, now time.Time) int {
+	createdDay := createdAt.UTC().Truncate(24 * time.Hour)
+	days := int(now.UTC().Truncate(24*time.Hour).Sub(createdDay)/(24*time.Hour)) + 1
+	if days < 1 {
+		return 1
+	}
+	windowDays := int(starSampleWindow / (24 * time.Hour))
+	if days > windowDays {
+		return windowDays
+	}
+	return days
+}
+
+// processTrafficSeries emits the individual traffic entries using their own timestamps. Entries older than the last
+// emitted one are skipped; the last emitted one is re-emitted as its counts grow until the day (or week) is over. Right
+// after a restore, the last emitted one is skipped as well, as it has already been emitted before the restart.
+func (plugin *GitHub) processTrafficSeries(a telegraf.Accumulator, repo string, measurement string, entries []*githubApi.TrafficData, lastTimestamp *time.Time, restored bool) {
+	for _, entry := range entries {
+		timestamp := entry.GetTimestamp().Time
+		if timestamp.Before(*lastTimestamp) || (restored && timestamp.Equal(*lastTimestamp)) {
+			continue
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		fields := make(map[string]interface{})
+		fields["count"] = entry.GetCount()
+		fields["uniques"] = entry.GetUniques()
+		a.AddFields(measurement, fields, tags, timestamp)
+		*lastTimestamp = timestamp
+	}
+}
+
+// processTrafficExport emits all daily traffic entries of the traffic window the way GitHub's Insights CSV export lists
+// them (one row per day with its date, count and uniques). All entries are re-emitted on every gather, so the stored
+// points always reflect GitHub's latest numbers and can be reconciled with the UI.
+func (plugin *GitHub) processTrafficExport(a telegraf.Accumulator, repo string, traffic string, entries []*githubApi.TrafficData) {
+	for _, entry := range entries {
+		timestamp := entry.GetTimestamp().Time
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["traffic"] = traffic
+		fields := make(map[string]interface{})
+		fields["date"] = timestamp.UTC().Format("2006-01-02")
+		fields["count"] = entry.GetCount()
+		fields["uniques"] = entry.GetUniques()
+		a.AddFields("github_traffic_export", fields, tags, timestamp)
+	}
+}
+
+func (plugin *GitHub) processReferrers(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	referrers, _, err := client.Repositories.ListTrafficReferrers(ctx, repoOwner, repoName)
+	if err != nil {
+		return err
+	}
+	for _, referrer := range referrers {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["referrer"] = referrer.GetReferrer()
+		fields := make(map[string]interface{})
+		fields["count"] = referrer.GetCount()
+		fields["uniques"] = referrer.GetUniques()
+		a.AddCounter("github_referrers", fields, tags)
+	}
+	return nil
+}
+
+func (plugin *GitHub) processPopularPaths(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	paths, _, err := client.Repositories.ListTrafficPaths(ctx, repoOwner, repoName)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["path"] = path.GetPath()
+		tags["title"] = path.GetTitle()
+		fields := make(map[string]interface{})
+		fields["count"] = path.GetCount()
+		fields["uniques"] = path.GetUniques()
+		a.AddCounter("github_paths", fields, tags)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// validate.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	githubApi "github.com/google/go-github/v44/github"
+)
+
+// validateAccess verifies the configured access token once during startup, so misconfigurations are reported clearly
+// instead of surfacing as failing gathers. A rejected token fails the startup; missing scopes, invisible repos and
+// missing push access (required for the traffic stats) are logged. If GitHub is not reachable at all, the validation is
+// skipped.
+func (plugin *GitHub) validateAccess(ctx context.Context, client *githubApi.Client) error {
+	if !plugin.appAuthentication() {
+		user, response, err := client.Users.Get(ctx, "")
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusUnauthorized {
+				return fmt.Errorf("github: Access token has been rejected: %v", err)
+			}
+			plugin.Log.Warnf("Skipping access validation as the API is not accessible: %v", err)
+			return nil
+		}
+		if plugin.Debug {
+			plugin.Log.Debugf("Authenticated as user '%s'...", user.GetLogin())
+		}
+		missingScopes := plugin.missingScopes(response.Header)
+		if len(missingScopes) > 0 {
+			plugin.Log.Warnf("Access token is missing the scopes required by the enabled stats: %s", strings.Join(missingScopes, ", "))
+		}
+	}
+	invisibleRepos, readOnlyRepos, err := plugin.checkRepoAccess(ctx, client)
+	if err != nil {
+		plugin.Log.Warnf("Skipping repo access validation: %v", err)
+		return nil
+	}
+	if len(invisibleRepos) > 0 {
+		plugin.Log.Errorf("Access token cannot see repos: %s", strings.Join(invisibleRepos, ", "))
+	}
+	if len(readOnlyRepos) > 0 {
+		plugin.Log.Warnf("Access token lacks the push access required for the traffic stats of repos: %s", strings.Join(readOnlyRepos, ", "))
+	}
+	return nil
+}
+
+// missingScopes determines the OAuth scopes required by the enabled stats but not granted to the access token. Only
+// classic tokens report their scopes; fine-grained tokens are not checked.
+func (plugin *GitHub) missingScopes(header http.Header) []string {
+	scopesHeader, classic := header["X-Oauth-Scopes"]
+	if !classic {
+		return nil
+	}
+	granted := make(map[string]bool)
+	for _, scope := range strings.Split(strings.Join(scopesHeader, ","), ",") {
+		granted[strings.TrimSpace(scope)] = true
+	}
+	missing := make([]string, 0)
+	if plugin.collectorEnabled(collectorTraffic) && !granted["repo"] && !granted["public_repo"] {
+		missing = append(missing, "repo (or public_repo)")
+	}
+	if len(plugin.Orgs) > 0 && !granted["admin:org"] && !granted["write:org"] && !granted["read:org"] {
+		missing = append(missing, "read:org")
+	}
+	if plugin.OrgWebhooks && !granted["admin:org_hook"] {
+		missing = append(missing, "admin:org_hook")
+	}
+	return missing
+}
+
+// checkRepoAccess looks up the explicitly configured repos (repo patterns are resolved via discovery and therefore
+// visible by definition) and returns the ones not visible to the access token as well as the ones the access token has
+// no push access to (if the traffic stats are enabled).
+func (plugin *GitHub) checkRepoAccess(ctx context.Context, client *githubApi.Client) ([]string, []string, error) {
+	invisibleRepos := make([]string, 0)
+	readOnlyRepos := make([]string, 0)
+	for _, repo := range plugin.Repos {
+		if isRepoPattern(repo) {
+			continue
+		}
+		repoOwner, repoName, err := plugin.splitRepoId(repo)
+		if err != nil {
+			return nil, nil, err
+		}
+		repoInfo, response, err := client.Repositories.Get(ctx, repoOwner, repoName)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				invisibleRepos = append(invisibleRepos, repo)
+				continue
+			}
+			return nil, nil, err
+		}
+		if plugin.collectorEnabled(collectorTraffic) && !repoInfo.GetPermissions()["push"] {
+			readOnlyRepos = append(readOnlyRepos, repo)
+		}
+	}
+	return invisibleRepos, readOnlyRepos, nil
+}
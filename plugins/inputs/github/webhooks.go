@@ -0,0 +1,72 @@
+// webhooks.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+func (plugin *GitHub) processOrgWebhooks(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, org string) error {
+	hooks := make([]*githubApi.Hook, 0)
+	opts := &githubApi.ListOptions{PerPage: 100}
+	for {
+		pageHooks, response, err := client.Organizations.ListHooks(ctx, org, opts)
+		if err != nil {
+			return err
+		}
+		hooks = append(hooks, pageHooks...)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	now := time.Now()
+	active := 0
+	failing := 0
+	for _, hook := range hooks {
+		deliveries, _, err := client.Organizations.ListHookDeliveries(ctx, org, hook.GetID(), &githubApi.ListCursorOptions{PerPage: 1})
+		if err != nil {
+			return err
+		}
+		if hook.GetActive() {
+			active++
+		}
+		tags := make(map[string]string)
+		tags["github_org"] = org
+		tags["hook_id"] = strconv.FormatInt(hook.GetID(), 10)
+		fields := make(map[string]interface{})
+		fields["active"] = hook.GetActive()
+		fields["events"] = len(hook.Events)
+		fields["has_deliveries"] = len(deliveries) > 0
+		if len(deliveries) > 0 {
+			lastDelivery := deliveries[0]
+			success := lastDelivery.GetStatusCode() >= 200 && lastDelivery.GetStatusCode() < 300
+			if !success {
+				failing++
+			}
+			fields["last_delivery_status_code"] = lastDelivery.GetStatusCode()
+			fields["last_delivery_success"] = success
+			fields["seconds_since_last_delivery"] = int64(now.Sub(lastDelivery.GetDeliveredAt().Time).Seconds())
+		}
+		a.AddCounter("github_org_webhook", fields, tags)
+	}
+	tags := make(map[string]string)
+	tags["github_org"] = org
+	fields := make(map[string]interface{})
+	fields["hooks"] = len(hooks)
+	fields["active_hooks"] = active
+	fields["inactive_hooks"] = len(hooks) - active
+	fields["failing_hooks"] = failing
+	a.AddCounter("github_org_webhooks", fields, tags)
+	return nil
+}
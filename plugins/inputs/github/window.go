@@ -0,0 +1,49 @@
+// window.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultWindow = "7d"
+
+// defaultAuditLogWindow is the lookback window of the audit log summary.
+const defaultAuditLogWindow = "1d"
+
+// parseWindow parses a lookback window given as Go duration (e.g. "36h") or as number of days ("7d") or weeks ("2w").
+func parseWindow(window string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(window, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(window, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	var duration time.Duration
+	if unit != 0 {
+		count, err := strconv.Atoi(window[:len(window)-1])
+		if err != nil {
+			return 0, fmt.Errorf("github: Invalid window '%s'", window)
+		}
+		duration = time.Duration(count) * unit
+	} else {
+		parsed, err := time.ParseDuration(window)
+		if err != nil {
+			return 0, fmt.Errorf("github: Invalid window '%s'", window)
+		}
+		duration = parsed
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("github: Invalid window '%s'", window)
+	}
+	return duration, nil
+}
@@ -0,0 +1,198 @@
+// workflows.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+)
+
+var workflowCronPattern = regexp.MustCompile(`(?m)^[\s-]*cron:\s*['"]?([^'"#\r\n]+?)['"]?\s*(?:#.*)?$`)
+
+func (plugin *GitHub) processWorkflowSchedules(ctx context.Context, client *githubApi.Client, a telegraf.Accumulator, repo string, repoOwner string, repoName string) error {
+	workflows, _, err := client.Actions.ListWorkflows(ctx, repoOwner, repoName, &githubApi.ListOptions{PerPage: 100})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, workflow := range workflows.Workflows {
+		if workflow.GetState() == "deleted" {
+			continue
+		}
+		schedules, err := plugin.getWorkflowSchedules(ctx, client, repoOwner, repoName, workflow)
+		if err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			continue
+		}
+		runs, _, err := client.Actions.ListWorkflowRunsByID(ctx, repoOwner, repoName, workflow.GetID(), &githubApi.ListWorkflowRunsOptions{Event: "schedule", ListOptions: githubApi.ListOptions{PerPage: 1}})
+		if err != nil {
+			return err
+		}
+		lastRun := workflow.GetCreatedAt().Time
+		if len(runs.WorkflowRuns) > 0 {
+			lastRun = runs.WorkflowRuns[0].GetCreatedAt().Time
+		}
+		nextRun := time.Time{}
+		for _, schedule := range schedules {
+			scheduleNextRun := schedule.next(lastRun)
+			if !scheduleNextRun.IsZero() && (nextRun.IsZero() || scheduleNextRun.Before(nextRun)) {
+				nextRun = scheduleNextRun
+			}
+		}
+		var drift int64
+		if !nextRun.IsZero() && now.After(nextRun) {
+			drift = int64(now.Sub(nextRun).Seconds())
+		}
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_workflow"] = workflow.GetName()
+		fields := make(map[string]interface{})
+		fields["schedule_count"] = len(schedules)
+		fields["seconds_since_last_run"] = int64(now.Sub(lastRun).Seconds())
+		fields["next_run_drift_seconds"] = drift
+		fields["disabled_inactivity"] = workflow.GetState() == "disabled_inactivity"
+		a.AddCounter("github_workflow_schedule", fields, tags)
+	}
+	return nil
+}
+
+// getWorkflowSchedules parses the cron schedules from the workflow's file. Dynamic workflows (e.g. Pages deployments,
+// Dependabot updates or the CodeQL default setup) as well as workflows whose file has been removed in the meantime have
+// no file to parse and therefore no schedules.
+func (plugin *GitHub) getWorkflowSchedules(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string, workflow *githubApi.Workflow) ([]*cronSchedule, error) {
+	if strings.HasPrefix(workflow.GetPath(), "dynamic/") {
+		return nil, nil
+	}
+	workflowFile, _, response, err := client.Repositories.GetContents(ctx, repoOwner, repoName, workflow.GetPath(), nil)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if workflowFile == nil {
+		return nil, nil
+	}
+	workflowContent, err := workflowFile.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*cronSchedule, 0)
+	for _, match := range workflowCronPattern.FindAllStringSubmatch(workflowContent, -1) {
+		schedule, err := parseCronSchedule(match[1])
+		if err != nil {
+			plugin.Log.Warnf("Ignoring schedule of workflow '%s': %v", workflow.GetPath(), err)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// listWindowWorkflowRuns lists the repo's workflow runs created within the window (newest first).
+func (plugin *GitHub) listWindowWorkflowRuns(ctx context.Context, client *githubApi.Client, repoOwner string, repoName string) ([]*githubApi.WorkflowRun, error) {
+	windowRuns := make([]*githubApi.WorkflowRun, 0)
+	created := ">=" + time.Now().Add(-plugin.window).UTC().Format("2006-01-02T15:04:05Z")
+	opts := &githubApi.ListWorkflowRunsOptions{Created: created, ListOptions: githubApi.ListOptions{PerPage: 100}}
+	for {
+		runs, response, err := client.Actions.ListRepositoryWorkflowRuns(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		windowRuns = append(windowRuns, runs.WorkflowRuns...)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	return windowRuns, nil
+}
+
+type workflowConcurrencyStats struct {
+	runs           int
+	cancelledRuns  int
+	supersededRuns int
+}
+
+// processWorkflowConcurrency counts the runs within the window cancelled due to their concurrency group. As the API does
+// not report the cancel reason, a cancelled run is considered a concurrency cancel if a newer run of the same workflow,
+// branch and event has been created before the cancelled run was completed.
+func (plugin *GitHub) processWorkflowConcurrency(a telegraf.Accumulator, repo string, windowRuns []*githubApi.WorkflowRun) {
+	runs := make([]*githubApi.WorkflowRun, len(windowRuns))
+	copy(runs, windowRuns)
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].GetCreatedAt().Before(runs[j].GetCreatedAt().Time)
+	})
+	workflowStats := make(map[string]*workflowConcurrencyStats)
+	groupRuns := make(map[string][]*githubApi.WorkflowRun)
+	for _, run := range runs {
+		stats := workflowStats[run.GetName()]
+		if stats == nil {
+			stats = &workflowConcurrencyStats{}
+			workflowStats[run.GetName()] = stats
+		}
+		stats.runs++
+		group := fmt.Sprintf("%d:%s:%s", run.GetWorkflowID(), run.GetHeadBranch(), run.GetEvent())
+		groupRuns[group] = append(groupRuns[group], run)
+	}
+	for _, runs := range groupRuns {
+		for i, run := range runs {
+			if run.GetConclusion() != "cancelled" {
+				continue
+			}
+			stats := workflowStats[run.GetName()]
+			stats.cancelledRuns++
+			if i+1 < len(runs) && !runs[i+1].GetCreatedAt().After(run.GetUpdatedAt().Time) {
+				stats.supersededRuns++
+			}
+		}
+	}
+	for workflow, stats := range workflowStats {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_workflow"] = workflow
+		fields := make(map[string]interface{})
+		fields["runs"] = stats.runs
+		fields["cancelled_runs"] = stats.cancelledRuns
+		fields["concurrency_cancelled_runs"] = stats.supersededRuns
+		a.AddCounter("github_workflow_concurrency", fields, tags)
+	}
+}
+
+// processWorkflowReruns counts the runs within the window that have been re-run (run attempt > 1) as a proxy for
+// flaky workflows.
+func (plugin *GitHub) processWorkflowReruns(a telegraf.Accumulator, repo string, runs []*githubApi.WorkflowRun) {
+	workflowRuns := make(map[string]int)
+	workflowReruns := make(map[string]int)
+	for _, run := range runs {
+		workflowRuns[run.GetName()]++
+		if run.GetRunAttempt() > 1 {
+			workflowReruns[run.GetName()]++
+		}
+	}
+	for workflow, runCount := range workflowRuns {
+		tags := make(map[string]string)
+		tags["github_repo"] = repo
+		tags["github_workflow"] = workflow
+		fields := make(map[string]interface{})
+		fields["runs"] = runCount
+		fields["rerun_runs"] = workflowReruns[workflow]
+		fields["rerun_ratio"] = float64(workflowReruns[workflow]) / float64(runCount)
+		a.AddCounter("github_workflow_reruns", fields, tags)
+	}
+}
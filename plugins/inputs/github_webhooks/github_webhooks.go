@@ -0,0 +1,227 @@
+// github_webhooks.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package githubwebhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+// maxPayloadSize is the maximum payload size GitHub delivers webhooks for.
+const maxPayloadSize = 25 * 1024 * 1024
+
+type GitHubWebhooks struct {
+	ServiceAddress string        `toml:"service_address"`
+	Path           string        `toml:"path"`
+	Secret         config.Secret `toml:"secret"`
+	Events         []string      `toml:"events"`
+	Timeout        int           `toml:"timeout"`
+	Debug          bool          `toml:"debug"`
+
+	Log telegraf.Logger
+
+	acc      telegraf.Accumulator
+	secret   []byte
+	events   map[string]bool
+	listener net.Listener
+	server   *http.Server
+}
+
+func NewGitHubWebhooks() *GitHubWebhooks {
+	return &GitHubWebhooks{
+		ServiceAddress: ":8080",
+		Path:           "/github",
+		Events:         []string{"star", "fork", "release", "push", "workflow_run", "issues"},
+		Timeout:        10,
+	}
+}
+
+func (plugin *GitHubWebhooks) SampleConfig() string {
+	return `
+  ## The address to listen on for webhook deliveries
+  # service_address = ":8080"
+  ## The path to receive webhook deliveries on
+  # path = "/github"
+  ## The webhook secret to validate the deliveries' signatures with (required)
+  secret = ""
+  ## The webhook events to turn into metrics (star, fork, release, push, workflow_run, issues); other events are ignored
+  # events = ["star", "fork", "release", "push", "workflow_run", "issues"]
+  ## The http read and write timeout to use (in seconds)
+  # timeout = 10
+  ## Enable debug output
+  # debug = false
+ `
+}
+
+func (plugin *GitHubWebhooks) Description() string {
+	return "Receive GitHub webhooks and turn them into metrics"
+}
+
+func (plugin *GitHubWebhooks) Init() error {
+	if plugin.Secret.Empty() {
+		return errors.New("github_webhooks: Missing webhook secret")
+	}
+	secret, err := plugin.Secret.Get()
+	if err != nil {
+		return fmt.Errorf("github_webhooks: Failed to resolve webhook secret: %v", err)
+	}
+	plugin.secret = []byte(secret.String())
+	secret.Destroy()
+	plugin.events = make(map[string]bool)
+	for _, event := range plugin.Events {
+		switch event {
+		case "star", "fork", "release", "push", "workflow_run", "issues":
+			plugin.events[event] = true
+		default:
+			return fmt.Errorf("github_webhooks: Invalid event '%s'", event)
+		}
+	}
+	return nil
+}
+
+func (plugin *GitHubWebhooks) Start(acc telegraf.Accumulator) error {
+	plugin.acc = acc
+	listener, err := net.Listen("tcp", plugin.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	plugin.listener = listener
+	mux := http.NewServeMux()
+	mux.Handle(plugin.Path, plugin)
+	plugin.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  time.Duration(plugin.Timeout) * time.Second,
+		WriteTimeout: time.Duration(plugin.Timeout) * time.Second,
+	}
+	go func() {
+		err := plugin.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			plugin.Log.Errorf("Failed to serve webhooks: %v", err)
+		}
+	}()
+	plugin.Log.Infof("Listening for webhooks on %s%s", listener.Addr(), plugin.Path)
+	return nil
+}
+
+func (plugin *GitHubWebhooks) Stop() {
+	if plugin.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(plugin.Timeout)*time.Second)
+	defer cancel()
+	err := plugin.server.Shutdown(ctx)
+	if err != nil {
+		plugin.Log.Warnf("Failed to shut down webhook server: %v", err)
+	}
+	plugin.server = nil
+}
+
+// Gather does nothing, as all metrics are added on webhook delivery.
+func (plugin *GitHubWebhooks) Gather(acc telegraf.Accumulator) error {
+	return nil
+}
+
+func (plugin *GitHubWebhooks) ServeHTTP(out http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(out, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request.Body = http.MaxBytesReader(out, request.Body, maxPayloadSize)
+	payload, err := githubApi.ValidatePayload(request, plugin.secret)
+	if err != nil {
+		plugin.Log.Warnf("Rejecting webhook delivery %s: %v", githubApi.DeliveryID(request), err)
+		http.Error(out, "Invalid payload", http.StatusUnauthorized)
+		return
+	}
+	eventType := githubApi.WebHookType(request)
+	if !plugin.events[eventType] {
+		if plugin.Debug {
+			plugin.Log.Infof("Ignoring webhook delivery %s of event '%s'", githubApi.DeliveryID(request), eventType)
+		}
+		out.WriteHeader(http.StatusNoContent)
+		return
+	}
+	event, err := githubApi.ParseWebHook(eventType, payload)
+	if err != nil {
+		plugin.Log.Warnf("Failed to decode webhook delivery %s: %v", githubApi.DeliveryID(request), err)
+		http.Error(out, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	if plugin.Debug {
+		plugin.Log.Infof("Processing webhook delivery %s of event '%s'", githubApi.DeliveryID(request), eventType)
+	}
+	plugin.processEvent(eventType, event)
+	out.WriteHeader(http.StatusNoContent)
+}
+
+// processEvent adds the metric for the given event. Besides the event specific fields, all metrics carry the repo's
+// current star, fork and open issue counts as reported by the event's payload.
+func (plugin *GitHubWebhooks) processEvent(eventType string, event interface{}) {
+	tags := make(map[string]string)
+	tags["event"] = eventType
+	fields := make(map[string]interface{})
+	fields["events"] = 1
+	switch typedEvent := event.(type) {
+	case *githubApi.StarEvent:
+		addRepoTagsAndFields(tags, fields, typedEvent.GetAction(), typedEvent.GetRepo())
+	case *githubApi.ForkEvent:
+		addRepoTagsAndFields(tags, fields, "", typedEvent.GetRepo())
+	case *githubApi.ReleaseEvent:
+		addRepoTagsAndFields(tags, fields, typedEvent.GetAction(), typedEvent.GetRepo())
+		tags["github_release"] = typedEvent.GetRelease().GetTagName()
+		fields["asset_count"] = len(typedEvent.GetRelease().Assets)
+		fields["prerelease"] = typedEvent.GetRelease().GetPrerelease()
+	case *githubApi.PushEvent:
+		repo := typedEvent.GetRepo()
+		tags["github_repo"] = repo.GetFullName()
+		fields["stargazers_count"] = repo.GetStargazersCount()
+		fields["forks_count"] = repo.GetForksCount()
+		fields["open_issues_count"] = repo.GetOpenIssuesCount()
+		fields["commits"] = len(typedEvent.Commits)
+		fields["forced"] = typedEvent.GetForced()
+	case *githubApi.WorkflowRunEvent:
+		addRepoTagsAndFields(tags, fields, typedEvent.GetAction(), typedEvent.GetRepo())
+		run := typedEvent.GetWorkflowRun()
+		tags["workflow"] = run.GetName()
+		fields["run_attempt"] = run.GetRunAttempt()
+		if typedEvent.GetAction() == "completed" {
+			tags["conclusion"] = run.GetConclusion()
+			if run.RunStartedAt != nil && run.UpdatedAt != nil {
+				fields["duration_seconds"] = run.GetUpdatedAt().Sub(run.GetRunStartedAt().Time).Seconds()
+			}
+		}
+	case *githubApi.IssuesEvent:
+		addRepoTagsAndFields(tags, fields, typedEvent.GetAction(), typedEvent.GetRepo())
+	}
+	plugin.acc.AddCounter("github_webhook", fields, tags)
+}
+
+func addRepoTagsAndFields(tags map[string]string, fields map[string]interface{}, action string, repo *githubApi.Repository) {
+	tags["github_repo"] = repo.GetFullName()
+	if action != "" {
+		tags["action"] = action
+	}
+	fields["stargazers_count"] = repo.GetStargazersCount()
+	fields["forks_count"] = repo.GetForksCount()
+	fields["open_issues_count"] = repo.GetOpenIssuesCount()
+}
+
+func init() {
+	inputs.Add("github_webhooks", func() telegraf.Input {
+		return NewGitHubWebhooks()
+	})
+}
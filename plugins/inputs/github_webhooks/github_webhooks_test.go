@@ -0,0 +1,178 @@
+// github_webhooks_test.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package githubwebhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "webhook_secret"
+
+func TestInit(t *testing.T) {
+	plugin := NewGitHubWebhooks()
+	require.Error(t, plugin.Init())
+	plugin.Secret = config.NewSecret([]byte(testSecret))
+	require.NoError(t, plugin.Init())
+	plugin.Events = []string{"gollum"}
+	require.Error(t, plugin.Init())
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := NewGitHubWebhooks()
+	sampleConfig := plugin.SampleConfig()
+	require.NotNil(t, sampleConfig)
+}
+
+func TestDescription(t *testing.T) {
+	plugin := NewGitHubWebhooks()
+	description := plugin.Description()
+	require.NotNil(t, description)
+}
+
+func TestServeHTTP(t *testing.T) {
+	plugin := createTestPlugin(t)
+	var a testutil.Accumulator
+	plugin.acc = &a
+
+	response := httptest.NewRecorder()
+	plugin.ServeHTTP(response, createTestDelivery(t, "http://localhost/github", "star", starEvent, testSecret))
+	require.Equal(t, http.StatusNoContent, response.Code)
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "event": "star", "action": "created"}
+	require.True(t, a.HasPoint("github_webhook", tags, "events", 1))
+	require.True(t, a.HasPoint("github_webhook", tags, "stargazers_count", 43))
+
+	response = httptest.NewRecorder()
+	plugin.ServeHTTP(response, createTestDelivery(t, "http://localhost/github", "star", starEvent, "wrong_secret"))
+	require.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = httptest.NewRecorder()
+	plugin.ServeHTTP(response, createTestDelivery(t, "http://localhost/github", "ping", `{"zen": "Keep it logically awesome."}`, testSecret))
+	require.Equal(t, http.StatusNoContent, response.Code)
+	require.Equal(t, 1, int(a.NMetrics()))
+}
+
+func TestStartStop(t *testing.T) {
+	plugin := createTestPlugin(t)
+	plugin.ServiceAddress = "127.0.0.1:0"
+	var a testutil.Accumulator
+	require.NoError(t, plugin.Start(&a))
+	defer plugin.Stop()
+
+	url := "http://" + plugin.listener.Addr().String() + "/github"
+	response, err := http.DefaultClient.Do(createTestDelivery(t, url, "workflow_run", workflowRunEvent, testSecret))
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusNoContent, response.StatusCode)
+	tags := map[string]string{"github_repo": "repo_owner/repo_name", "event": "workflow_run", "action": "completed", "workflow": "CI", "conclusion": "failure"}
+	require.True(t, a.HasPoint("github_webhook", tags, "duration_seconds", 90.0))
+	require.True(t, a.HasPoint("github_webhook", tags, "run_attempt", 2))
+}
+
+func createTestPlugin(t *testing.T) *GitHubWebhooks {
+	plugin := NewGitHubWebhooks()
+	plugin.Secret = config.NewSecret([]byte(testSecret))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = true
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+func createTestDelivery(t *testing.T, url string, event string, payload string, secret string) *http.Request {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(payload))
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-GitHub-Event", event)
+	request.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	request.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return request
+}
+
+const starEvent = `
+{
+  "action": "created",
+  "starred_at": "2022-10-20T00:00:00Z",
+  "repository": {
+    "full_name": "repo_owner/repo_name",
+    "stargazers_count": 43,
+    "forks_count": 12,
+    "open_issues_count": 3
+  }
+}
+`
+
+const workflowRunEvent = `
+{
+  "action": "completed",
+  "workflow_run": {
+    "name": "CI",
+    "conclusion": "failure",
+    "run_attempt": 2,
+    "run_started_at": "2022-10-20T10:00:00Z",
+    "updated_at": "2022-10-20T10:01:30Z"
+  },
+  "repository": {
+    "full_name": "repo_owner/repo_name",
+    "stargazers_count": 43,
+    "forks_count": 12,
+    "open_issues_count": 3
+  }
+}
+`
+
+func createDummyLogger() *dummyLogger {
+	log.SetOutput(os.Stderr)
+	return &dummyLogger{}
+}
+
+type dummyLogger struct{}
+
+func (l *dummyLogger) Errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Error(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Debugf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Debug(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Warnf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Warn(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Infof(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Info(args ...interface{}) {
+	log.Print(args...)
+}
@@ -0,0 +1,322 @@
+// github.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	modeStatus   = "status"
+	modeCheckRun = "check_run"
+)
+
+// Threshold defines a metric field condition published as commit status or check run.
+type Threshold struct {
+	Name        string  `toml:"name"`
+	Measurement string  `toml:"measurement"`
+	Field       string  `toml:"field"`
+	Operator    string  `toml:"operator"`
+	Value       float64 `toml:"value"`
+	Description string  `toml:"description"`
+}
+
+type GitHub struct {
+	APIBaseURL  string        `toml:"api_base_url"`
+	AccessToken config.Secret `toml:"access_token"`
+	Repo        string        `toml:"repo"`
+	RepoTag     string        `toml:"repo_tag"`
+	Ref         string        `toml:"ref"`
+	SHATag      string        `toml:"sha_tag"`
+	Mode        string        `toml:"mode"`
+	Context     string        `toml:"context"`
+	Thresholds  []*Threshold  `toml:"thresholds"`
+	Timeout     int           `toml:"timeout"`
+	Debug       bool          `toml:"debug"`
+
+	Log telegraf.Logger
+
+	client *githubApi.Client
+	states map[string]bool
+}
+
+func NewGitHub() *GitHub {
+	return &GitHub{
+		RepoTag:    "github_repo",
+		Ref:        "main",
+		SHATag:     "sha",
+		Mode:       modeStatus,
+		Context:    "telegraf",
+		Thresholds: []*Threshold{},
+		Timeout:    10,
+		states:     make(map[string]bool),
+	}
+}
+
+func (plugin *GitHub) SampleConfig() string {
+	return `
+  ## The API base URL to use for API access (empty URL defaults to https://api.github.com/)
+  # api_base_url = ""
+  ## The Personal Access Token to use for API access (check runs require a GitHub App installation token)
+  access_token = ""
+  ## The repository (<owner>/<repo>) to publish to if a metric does not carry the repo tag below
+  # repo = ""
+  # repo_tag = "github_repo"
+  ## The ref (branch, tag or sha) to publish to if a metric does not carry the sha tag below (check runs are published
+  ## to the commit the ref currently points to)
+  # ref = "main"
+  # sha_tag = "sha"
+  ## Publish the threshold results as commit status (status) or as check run (check_run)
+  # mode = "status"
+  ## The status context (respectively check run name) prefix
+  # context = "telegraf"
+  ## The http timeout to use (in seconds)
+  # timeout = 10
+  ## Enable debug output
+  # debug = false
+  ## The thresholds to publish (operator is one of >, >=, <, <=, ==, !=); a result is only published on change
+  # [[outputs.github.thresholds]]
+  #   name = "downloads"
+  #   measurement = "github_info"
+  #   field = "total_download_count"
+  #   operator = ">="
+  #   value = 1000.0
+  #   description = "Download target met"
+ `
+}
+
+func (plugin *GitHub) Description() string {
+	return "Publish metric thresholds as GitHub commit status or check run"
+}
+
+func (plugin *GitHub) Init() error {
+	if plugin.Mode != modeStatus && plugin.Mode != modeCheckRun {
+		return fmt.Errorf("github: Invalid mode '%s'", plugin.Mode)
+	}
+	for _, threshold := range plugin.Thresholds {
+		if _, err := compare(threshold.Operator, 0, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (plugin *GitHub) Connect() error {
+	if plugin.Debug {
+		plugin.Log.Debug("Creating GitHub client...")
+	}
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: time.Duration(plugin.Timeout) * time.Second,
+		},
+		Timeout: time.Duration(plugin.Timeout) * time.Second,
+	}
+	if !plugin.AccessToken.Empty() {
+		httpClient.Transport = &tokenTransport{base: httpClient.Transport, token: &plugin.AccessToken}
+	}
+	if plugin.APIBaseURL != "" {
+		client, err := githubApi.NewEnterpriseClient(plugin.APIBaseURL, "", httpClient)
+		if err != nil {
+			return err
+		}
+		plugin.client = client
+	} else {
+		plugin.client = githubApi.NewClient(httpClient)
+	}
+	return nil
+}
+
+func (plugin *GitHub) Close() error {
+	plugin.client = nil
+	return nil
+}
+
+func (plugin *GitHub) Write(metrics []telegraf.Metric) error {
+	ctx := context.Background()
+	// refs are resolved at most once per write
+	shas := make(map[string]string)
+	for _, metric := range metrics {
+		for _, threshold := range plugin.Thresholds {
+			if threshold.Measurement != metric.Name() {
+				continue
+			}
+			value, ok := metric.GetField(threshold.Field)
+			if !ok {
+				continue
+			}
+			err := plugin.publish(ctx, metric, threshold, value, shas)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (plugin *GitHub) publish(ctx context.Context, metric telegraf.Metric, threshold *Threshold, value interface{}, shas map[string]string) error {
+	repo, ok := metric.GetTag(plugin.RepoTag)
+	if !ok {
+		repo = plugin.Repo
+	}
+	repoOwner, repoName, found := strings.Cut(repo, "/")
+	if !found || repoOwner == "" || repoName == "" {
+		plugin.Log.Warnf("Ignoring metric '%s' without valid repo", metric.Name())
+		return nil
+	}
+	ref, ok := metric.GetTag(plugin.SHATag)
+	if !ok {
+		ref = plugin.Ref
+		// check runs require the head commit's sha
+		if plugin.Mode == modeCheckRun {
+			sha, err := plugin.resolveRef(ctx, shas, repo, repoOwner, repoName, ref)
+			if err != nil {
+				return err
+			}
+			ref = sha
+		}
+	}
+	floatValue, ok := toFloat(value)
+	if !ok {
+		plugin.Log.Warnf("Ignoring non-numeric field '%s' of metric '%s'", threshold.Field, metric.Name())
+		return nil
+	}
+	passed, err := compare(threshold.Operator, floatValue, threshold.Value)
+	if err != nil {
+		return err
+	}
+	stateKey := repo + "@" + ref + "#" + threshold.Name
+	previous, known := plugin.states[stateKey]
+	if known && previous == passed {
+		return nil
+	}
+	name := plugin.Context + "/" + threshold.Name
+	description := fmt.Sprintf("%s: %v %s %v", threshold.Field, value, threshold.Operator, threshold.Value)
+	if threshold.Description != "" {
+		description = threshold.Description + " (" + description + ")"
+	}
+	if plugin.Debug {
+		plugin.Log.Infof("Publishing %s '%s' for %s@%s: %v", plugin.Mode, name, repo, ref, passed)
+	}
+	if plugin.Mode == modeCheckRun {
+		conclusion := "failure"
+		if passed {
+			conclusion = "success"
+		}
+		_, _, err = plugin.client.Checks.CreateCheckRun(ctx, repoOwner, repoName, githubApi.CreateCheckRunOptions{
+			Name:       name,
+			HeadSHA:    ref,
+			Status:     githubApi.String("completed"),
+			Conclusion: githubApi.String(conclusion),
+			Output: &githubApi.CheckRunOutput{
+				Title:   githubApi.String(name),
+				Summary: githubApi.String(description),
+			},
+		})
+	} else {
+		state := "failure"
+		if passed {
+			state = "success"
+		}
+		_, _, err = plugin.client.Repositories.CreateStatus(ctx, repoOwner, repoName, ref, &githubApi.RepoStatus{
+			State:       githubApi.String(state),
+			Context:     githubApi.String(name),
+			Description: githubApi.String(description),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	plugin.states[stateKey] = passed
+	return nil
+}
+
+// resolveRef determines the sha of the commit the given ref currently points to.
+func (plugin *GitHub) resolveRef(ctx context.Context, shas map[string]string, repo string, repoOwner string, repoName string, ref string) (string, error) {
+	shaKey := repo + "@" + ref
+	sha, resolved := shas[shaKey]
+	if resolved {
+		return sha, nil
+	}
+	if plugin.Debug {
+		plugin.Log.Infof("Resolving ref %s@%s...", repo, ref)
+	}
+	sha, _, err := plugin.client.Repositories.GetCommitSHA1(ctx, repoOwner, repoName, ref, "")
+	if err != nil {
+		return "", fmt.Errorf("github: Failed to resolve ref '%s' of repo '%s': %v", ref, repo, err)
+	}
+	shas[shaKey] = sha
+	return sha, nil
+}
+
+func compare(operator string, value float64, threshold float64) (bool, error) {
+	switch operator {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	}
+	return false, fmt.Errorf("github: Invalid threshold operator '%s'", operator)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int64:
+		return float64(typedValue), true
+	case uint64:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	case bool:
+		if typedValue {
+			return 1.0, true
+		}
+		return 0.0, true
+	}
+	return 0.0, false
+}
+
+// tokenTransport authenticates requests with the configured access token (resolving the token secret per request).
+type tokenTransport struct {
+	base  http.RoundTripper
+	token *config.Secret
+}
+
+func (transport *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := transport.token.Get()
+	if err != nil {
+		return nil, fmt.Errorf("github: Failed to resolve access token: %v", err)
+	}
+	defer token.Destroy()
+	authorizedRequest := request.Clone(request.Context())
+	authorizedRequest.Header.Set("Authorization", "Bearer "+token.String())
+	return transport.base.RoundTrip(authorizedRequest)
+}
+
+func init() {
+	outputs.Add("github", func() telegraf.Output {
+		return NewGitHub()
+	})
+}
@@ -0,0 +1,167 @@
+// github_test.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	plugin := NewGitHub()
+	require.NoError(t, plugin.Init())
+	plugin.Mode = "comment"
+	require.Error(t, plugin.Init())
+	plugin.Mode = modeStatus
+	plugin.Thresholds = []*Threshold{{Name: "downloads", Operator: "=>"}}
+	require.Error(t, plugin.Init())
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := NewGitHub()
+	sampleConfig := plugin.SampleConfig()
+	require.NotNil(t, sampleConfig)
+}
+
+func TestDescription(t *testing.T) {
+	plugin := NewGitHub()
+	description := plugin.Description()
+	require.NotNil(t, description)
+}
+
+func TestWriteStatus(t *testing.T) {
+	testServerHandler := &testServerHandler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Thresholds = []*Threshold{{Name: "downloads", Measurement: "github_info", Field: "total_download_count", Operator: ">=", Value: 1000}}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{createTestMetric(999), createTestMetric(999)}))
+	require.Equal(t, []string{"/api/v3/repos/repo_owner/repo_name/statuses/main"}, testServerHandler.requests)
+	require.Equal(t, "failure", testServerHandler.bodies[0]["state"])
+	require.Equal(t, "telegraf/downloads", testServerHandler.bodies[0]["context"])
+	require.NoError(t, plugin.Write([]telegraf.Metric{createTestMetric(1000)}))
+	require.Len(t, testServerHandler.requests, 2)
+	require.Equal(t, "success", testServerHandler.bodies[1]["state"])
+}
+
+func TestWriteCheckRun(t *testing.T) {
+	testServerHandler := &testServerHandler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHub()
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Mode = modeCheckRun
+	plugin.Thresholds = []*Threshold{{Name: "downloads", Measurement: "github_info", Field: "total_download_count", Operator: ">", Value: 100}}
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{createTestMetric(999), createTestMetric(998)}))
+	require.Equal(t, []string{"/api/v3/repos/repo_owner/repo_name/commits/main", "/api/v3/repos/repo_owner/repo_name/check-runs"}, testServerHandler.requests)
+	require.Equal(t, "success", testServerHandler.bodies[1]["conclusion"])
+	require.Equal(t, testCommitSHA, testServerHandler.bodies[1]["head_sha"])
+	require.Equal(t, "Bearer secret_token", testServerHandler.authorization)
+}
+
+func createTestMetric(downloads int64) telegraf.Metric {
+	tags := map[string]string{"github_repo": "repo_owner/repo_name"}
+	fields := map[string]interface{}{"total_download_count": downloads}
+	return metric.New("github_info", tags, fields, time.Now())
+}
+
+func createDummyLogger() *dummyLogger {
+	log.SetOutput(os.Stderr)
+	return &dummyLogger{}
+}
+
+type dummyLogger struct{}
+
+func (l *dummyLogger) Errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Error(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Debugf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Debug(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Warnf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Warn(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Infof(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Info(args ...interface{}) {
+	log.Print(args...)
+}
+
+const testCommitSHA = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+
+type testServerHandler struct {
+	Debug         bool
+	requests      []string
+	bodies        []map[string]interface{}
+	authorization string
+}
+
+func (tsh *testServerHandler) ServeHTTP(out http.ResponseWriter, request *http.Request) {
+	requestURL := request.URL.String()
+	if tsh.Debug {
+		log.Printf("test: request URL: %s", requestURL)
+	}
+	body, _ := io.ReadAll(request.Body)
+	decoded := make(map[string]interface{})
+	_ = json.Unmarshal(body, &decoded)
+	tsh.requests = append(tsh.requests, requestURL)
+	tsh.bodies = append(tsh.bodies, decoded)
+	tsh.authorization = request.Header.Get("Authorization")
+	if request.Method == http.MethodGet && strings.Contains(requestURL, "/commits/") {
+		out.WriteHeader(http.StatusOK)
+		_, _ = out.Write([]byte(testCommitSHA))
+		return
+	}
+	out.Header().Add("Content-Type", "application/json")
+	out.WriteHeader(http.StatusCreated)
+	_, _ = out.Write([]byte(`{"id": 1}`))
+}
@@ -0,0 +1,181 @@
+// github_enrich.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package githubenrich
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	githubApi "github.com/google/go-github/v44/github"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+type GitHubEnrich struct {
+	APIBaseURL  string        `toml:"api_base_url"`
+	AccessToken config.Secret `toml:"access_token"`
+	RepoTag     string        `toml:"repo_tag"`
+	CacheTTL    int           `toml:"cache_ttl"`
+	Timeout     int           `toml:"timeout"`
+	Debug       bool          `toml:"debug"`
+
+	Log telegraf.Logger
+
+	client *githubApi.Client
+	cache  map[string]*repoMetadata
+	now    func() time.Time
+}
+
+// failedLookupTTL is the delay before a failed lookup is retried.
+const failedLookupTTL = 5 * time.Minute
+
+// repoMetadata holds the looked up tags of a single repo.
+type repoMetadata struct {
+	tags    map[string]string
+	expires time.Time
+}
+
+func NewGitHubEnrich() *GitHubEnrich {
+	return &GitHubEnrich{
+		RepoTag:  "github_repo",
+		CacheTTL: 3600,
+		Timeout:  10,
+		cache:    make(map[string]*repoMetadata),
+		now:      time.Now,
+	}
+}
+
+func (plugin *GitHubEnrich) SampleConfig() string {
+	return `
+  ## The API base URL to use for API access (empty URL defaults to https://api.github.com/)
+  # api_base_url = ""
+  ## The Personal Access Token to use for API access
+  # access_token = ""
+  ## The tag containing the repository (<owner>/<repo>) to look up
+  # repo_tag = "github_repo"
+  ## How long to cache the looked up repository metadata (in seconds)
+  # cache_ttl = 3600
+  ## The http timeout to use (in seconds)
+  # timeout = 10
+  ## Enable debug output
+  # debug = false
+ `
+}
+
+func (plugin *GitHubEnrich) Description() string {
+	return "Enrich metrics with GitHub repository metadata (owner, language, license, topics)"
+}
+
+func (plugin *GitHubEnrich) Init() error {
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: time.Duration(plugin.Timeout) * time.Second,
+		},
+		Timeout: time.Duration(plugin.Timeout) * time.Second,
+	}
+	if !plugin.AccessToken.Empty() {
+		httpClient.Transport = &tokenTransport{base: httpClient.Transport, token: &plugin.AccessToken}
+	}
+	if plugin.APIBaseURL != "" {
+		client, err := githubApi.NewEnterpriseClient(plugin.APIBaseURL, "", httpClient)
+		if err != nil {
+			return err
+		}
+		plugin.client = client
+	} else {
+		plugin.client = githubApi.NewClient(httpClient)
+	}
+	return nil
+}
+
+func (plugin *GitHubEnrich) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, metric := range in {
+		repo, ok := metric.GetTag(plugin.RepoTag)
+		if !ok {
+			continue
+		}
+		metadata := plugin.lookup(repo)
+		if metadata == nil {
+			continue
+		}
+		for key, value := range metadata.tags {
+			if value != "" {
+				metric.AddTag(key, value)
+			}
+		}
+	}
+	return in
+}
+
+// lookup returns the (cached) metadata of the given repo or nil if the repo identifier is invalid. Failed lookups are
+// cached as well (without tags, unless outdated ones are available) for a short period.
+func (plugin *GitHubEnrich) lookup(repo string) *repoMetadata {
+	now := plugin.now()
+	metadata, cached := plugin.cache[repo]
+	if cached && now.Before(metadata.expires) {
+		return metadata
+	}
+	repoOwner, repoName, found := strings.Cut(repo, "/")
+	if !found {
+		plugin.Log.Warnf("Ignoring invalid repo identifier '%s'", repo)
+		return nil
+	}
+	if plugin.Debug {
+		plugin.Log.Infof("Looking up repo: %s", repo)
+	}
+	repoInfo, _, err := plugin.client.Repositories.Get(context.Background(), repoOwner, repoName)
+	if err != nil {
+		plugin.Log.Errorf("Failed to look up repo '%s': %v", repo, err)
+		// keep using outdated metadata (if any) until the lookup succeeds again, but retry only after a delay
+		failed := &repoMetadata{expires: now.Add(failedLookupTTL)}
+		if metadata != nil {
+			failed.tags = metadata.tags
+		}
+		plugin.cache[repo] = failed
+		return failed
+	}
+	metadata = &repoMetadata{
+		tags: map[string]string{
+			"github_owner":    repoInfo.GetOwner().GetLogin(),
+			"github_language": repoInfo.GetLanguage(),
+			"github_license":  repoInfo.GetLicense().GetSPDXID(),
+			"github_topics":   strings.Join(repoInfo.Topics, ","),
+		},
+		expires: now.Add(time.Duration(plugin.CacheTTL) * time.Second),
+	}
+	plugin.cache[repo] = metadata
+	return metadata
+}
+
+// tokenTransport authenticates requests with the configured access token (resolving the token secret per request).
+type tokenTransport struct {
+	base  http.RoundTripper
+	token *config.Secret
+}
+
+func (transport *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := transport.token.Get()
+	if err != nil {
+		return nil, fmt.Errorf("github_enrich: Failed to resolve access token: %v", err)
+	}
+	defer token.Destroy()
+	authorizedRequest := request.Clone(request.Context())
+	authorizedRequest.Header.Set("Authorization", "Bearer "+token.String())
+	return transport.base.RoundTrip(authorizedRequest)
+}
+
+func init() {
+	processors.Add("github_enrich", func() telegraf.Processor {
+		return NewGitHubEnrich()
+	})
+}
@@ -0,0 +1,163 @@
+// github_enrich_test.go
+//
+// Copyright (C) 2022-2024 Holger de Carne
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package githubenrich
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	plugin := NewGitHubEnrich()
+	require.NoError(t, plugin.Init())
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := NewGitHubEnrich()
+	sampleConfig := plugin.SampleConfig()
+	require.NotNil(t, sampleConfig)
+}
+
+func TestDescription(t *testing.T) {
+	plugin := NewGitHubEnrich()
+	description := plugin.Description()
+	require.NotNil(t, description)
+}
+
+func TestApply(t *testing.T) {
+	testServerHandler := &testServerHandler{Debug: true}
+	testServer := httptest.NewServer(testServerHandler)
+	defer testServer.Close()
+	plugin := NewGitHubEnrich()
+	plugin.APIBaseURL = testServer.URL
+	plugin.AccessToken = config.NewSecret([]byte("secret_token"))
+	plugin.Log = createDummyLogger()
+	plugin.Debug = testServerHandler.Debug
+	now := time.Now()
+	plugin.now = func() time.Time { return now }
+	require.NoError(t, plugin.Init())
+
+	metrics := plugin.Apply(createTestMetric("repo_owner/repo_name"), createTestMetric("repo_owner/repo_name"), createTestMetric("repo_owner/unknown_repo"))
+	require.Len(t, metrics, 3)
+	for _, enriched := range metrics[:2] {
+		require.Equal(t, map[string]string{
+			"github_repo":     "repo_owner/repo_name",
+			"github_owner":    "repo_owner",
+			"github_language": "Go",
+			"github_license":  "MIT",
+			"github_topics":   "telegraf,monitoring",
+		}, enriched.Tags())
+	}
+	require.Equal(t, map[string]string{"github_repo": "repo_owner/unknown_repo"}, metrics[2].Tags())
+	require.Equal(t, 2, testServerHandler.requests)
+	plugin.Apply(createTestMetric("repo_owner/unknown_repo"))
+	require.Equal(t, 2, testServerHandler.requests)
+
+	now = now.Add(failedLookupTTL)
+	plugin.Apply(createTestMetric("repo_owner/unknown_repo"))
+	require.Equal(t, 3, testServerHandler.requests)
+
+	now = now.Add(2 * time.Hour)
+	plugin.Apply(createTestMetric("repo_owner/repo_name"))
+	require.Equal(t, 4, testServerHandler.requests)
+	require.Equal(t, "Bearer secret_token", testServerHandler.authorization)
+}
+
+func createTestMetric(repo string) telegraf.Metric {
+	return metric.New("ci_build", map[string]string{"github_repo": repo}, map[string]interface{}{"duration": 42}, time.Now())
+}
+
+func createDummyLogger() *dummyLogger {
+	log.SetOutput(os.Stderr)
+	return &dummyLogger{}
+}
+
+type dummyLogger struct{}
+
+func (l *dummyLogger) Errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Error(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Debugf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Debug(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Warnf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Warn(args ...interface{}) {
+	log.Print(args...)
+}
+
+func (l *dummyLogger) Infof(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (l *dummyLogger) Info(args ...interface{}) {
+	log.Print(args...)
+}
+
+type testServerHandler struct {
+	Debug         bool
+	requests      int
+	authorization string
+}
+
+func (tsh *testServerHandler) ServeHTTP(out http.ResponseWriter, request *http.Request) {
+	requestURL := request.URL.String()
+	if tsh.Debug {
+		log.Printf("test: request URL: %s", requestURL)
+	}
+	tsh.requests++
+	tsh.authorization = request.Header.Get("Authorization")
+	if requestURL == "/api/v3/repos/repo_owner/repo_name" {
+		tsh.serveRepositoryInfo(out, request)
+	} else {
+		out.WriteHeader(http.StatusNotFound)
+	}
+}
+
+const testRepositoryInfo = `
+{
+	"id": 1,
+	"name": "repo_name",
+	"full_name": "repo_owner/repo_name",
+	"owner": {
+	  "login": "repo_owner"
+	},
+	"language": "Go",
+	"license": {
+	  "key": "mit",
+	  "spdx_id": "MIT"
+	},
+	"topics": ["telegraf", "monitoring"]
+}
+`
+
+func (tsh *testServerHandler) serveRepositoryInfo(out http.ResponseWriter, request *http.Request) {
+	out.Header().Add("Content-Type", "application/json")
+	_, _ = out.Write([]byte(testRepositoryInfo))
+}